@@ -3,7 +3,9 @@ package main
 import (
 	_ "embed"
 	"fmt"
+	"io"
 	"slices"
+	"strconv"
 	"strings"
 	"time"
 
@@ -12,23 +14,47 @@ import (
 
 // parse the raw text into two slices of ints, each sorted
 func parse(raw_text string) ([]int, []int) {
-	lines := strings.Split(strings.TrimSpace(raw_text), "\n")
+	l, r, err := read_lists(strings.NewReader(raw_text))
+	if err != nil {
+		panic(err)
+	}
+	return l, r
+}
 
-	// Create the output types
-	l := make([]int, len(lines))
-	r := make([]int, len(lines))
+// read_lists reads the two columns of numbers one line at a time, so the whole input
+// never has to be in memory. Both slices are returned sorted.
+func read_lists(input io.Reader) ([]int, []int, error) {
+	var l, r []int
 
-	for idx, line := range lines {
+	lr := utils.NewLineReader(input, 0)
+	for line_num, line := range lr.Lines() {
+		if line == "" {
+			continue
+		}
 		parts := strings.Fields(line)
-		l[idx] = utils.ParseInt(parts[0])
-		r[idx] = utils.ParseInt(parts[1])
+		if len(parts) != 2 {
+			return nil, nil, fmt.Errorf("line %d: expected 2 numbers, found %d", line_num, len(parts))
+		}
+		left, err := strconv.Atoi(parts[0])
+		if err != nil {
+			return nil, nil, fmt.Errorf("line %d: %w", line_num, err)
+		}
+		right, err := strconv.Atoi(parts[1])
+		if err != nil {
+			return nil, nil, fmt.Errorf("line %d: %w", line_num, err)
+		}
+		l = append(l, left)
+		r = append(r, right)
+	}
+	if err := lr.Err(); err != nil {
+		return nil, nil, err
 	}
 
 	// Sort the slices
 	slices.Sort(l)
 	slices.Sort(r)
 
-	return l, r
+	return l, r, nil
 }
 
 func abs(x int) int {
@@ -52,13 +78,7 @@ func part1(l, r []int) int {
 // appears in the right list.
 func part2(l, r []int) int {
 	// Count the number of times each item appears in the right list
-	count := make(map[int]int, len(l))
-
-	// Fill the map
-	for _, ri := range r {
-		// The default value is the zero value, so we can just add to it
-		count[ri] += 1
-	}
+	count := utils.CountAll(slices.Values(r))
 
 	// For each item in the left, find its frequency, and multiply by it
 	res := 0
@@ -1,7 +1,10 @@
 package main
 
 import (
+	"strconv"
+	"strings"
 	"testing"
+	"testing/iotest"
 
 	"github.com/stretchr/testify/assert"
 )
@@ -73,3 +76,16 @@ func BenchmarkPart2(b *testing.B) {
 		})
 	}
 }
+
+func TestReadLists(t *testing.T) {
+	l, r, err := read_lists(iotest.OneByteReader(strings.NewReader("3   4\r\n4   3\r\n\r\n2   5\r\n")))
+	assert.NoError(t, err)
+	assert.Equal(t, []int{2, 3, 4}, l)
+	assert.Equal(t, []int{3, 4, 5}, r)
+
+	_, _, err = read_lists(strings.NewReader("3   4\n4\n"))
+	assert.EqualError(t, err, "line 2: expected 2 numbers, found 1")
+
+	_, _, err = read_lists(strings.NewReader("3   4\n4   x\n"))
+	assert.ErrorIs(t, err, strconv.ErrSyntax)
+}
@@ -10,15 +10,9 @@ import (
 )
 
 func parse(raw_text string) [][]int {
-	lines := strings.Split(strings.TrimSpace(raw_text), "\n")
-
-	res := make([][]int, len(lines))
-	for idx, line := range lines {
-		nums := strings.Fields(line)
-		res[idx] = make([]int, len(nums))
-		for i, num := range nums {
-			res[idx][i] = utils.ParseInt(num)
-		}
+	res, err := utils.ExtractIntsPerLine(strings.TrimSpace(raw_text), utils.IntOptions{})
+	if err != nil {
+		panic(err)
 	}
 	return res
 }
@@ -106,12 +100,9 @@ func report_is_good_p2(report []int, diffs []int) bool {
 		return true
 	}
 
-	new_report := make([]int, len(report)-1)
-	// Try removing each element and checking if it is valid
-	for idx := range report {
-		// Create a new report by removing the element at index i
-		copy(new_report, report[:idx])
-		copy(new_report[idx:], report[idx+1:])
+	// Try removing each element and checking if it is valid. Keeping all but one of the
+	// levels is the same as removing each one in turn.
+	for new_report := range utils.Combinations(report, len(report)-1) {
 		new_diffs := diff(new_report)
 		if report_is_good(new_diffs) {
 			return true
@@ -13,10 +13,9 @@ import (
 var mul_pattern *regexp.Regexp = regexp.MustCompile(`mul\((\d+),(\d+)\)`)
 
 func part1(input string) int {
-	matches := utils.GetGroups(mul_pattern, input)
 	// Parse each group into digits, multiply them, and sum the results
 	sum := 0
-	for _, match := range matches {
+	for match := range utils.AllGroups(mul_pattern, input) {
 		a := utils.ParseInt(match[0])
 		b := utils.ParseInt(match[1])
 		sum += a * b
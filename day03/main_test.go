@@ -14,6 +14,9 @@ func TestGetMatches(t *testing.T) {
 	// Do we get exactly 4 matches from the test input?
 	matches := utils.GetGroups(mul_pattern, test_input)
 	assert.Equal(t, 4, len(matches))
+	for _, match := range matches {
+		assert.Len(t, match, 2)
+	}
 }
 
 func TestPart1(t *testing.T) {
@@ -3,183 +3,46 @@ package main
 import (
 	_ "embed"
 	"fmt"
-	"strings"
 	"time"
+
+	"github.com/natemcintosh/aoc_2024/utils"
 )
 
+// Board is the word search, stored as a grid of letters
 type Board struct {
-	// The raw text of the board
-	text string
-
-	// The width of the board
-	width int
-
-	// The height of the board
-	height int
+	utils.Grid[byte]
 }
 
 func NewBoard(raw_input string) Board {
-	width := strings.Index(raw_input, "\n")
-	if width == -1 {
-		panic("Did not find any new line breaks")
-	}
-
-	height := strings.Count(raw_input, "\n")
-
-	// We don't want any new line breaks in the text, because they'll mess up the indexing
-	ri := strings.ReplaceAll(strings.TrimSpace(raw_input), "\n", "")
-	return Board{
-		text:   ri,
-		width:  width,
-		height: height,
-	}
+	return Board{utils.ParseGrid(raw_input, func(r rune) byte { return byte(r) })}
 }
 
 // The letters that spell "XMAS", but in integer form. Would prefer that this is a const,
 // but Go arrays aren't constant.
 var XMAS [4]byte = [4]byte{'X', 'M', 'A', 'S'}
 
+// spells_from checks if `to_find` is spelled out starting at (row, col) and going in the
+// direction (d_row, d_col)
+func (b Board) spells_from(row, col, d_row, d_col int, to_find []byte) bool {
+	n_matched := 0
+	for letter := range b.Walk(row, col, d_row, d_col) {
+		if n_matched == len(to_find) || letter != to_find[n_matched] {
+			break
+		}
+		n_matched += 1
+	}
+	return n_matched == len(to_find)
+}
+
 // CheckAllDirections checks if the letters in any of the cardinal or diagonal directions.
 // Return the number of times `to_find` is spelled in any of the directions.
 func (b Board) CheckAllDirections(idx int, to_find []byte) int {
 	// Get the row and column indices from the linear index
-	row_idx := idx / b.width
-	col_idx := idx % b.width
+	row_idx, col_idx := b.RowCol(idx)
 	total_count := 0
 
-	// First, check Up
-	if row_idx < 3 {
-		// Don't look up
-	} else {
-		all_good := true
-		for i, letter := range to_find {
-			li := idx - (b.width * i)
-			if b.text[li] != letter {
-				all_good = false
-				break
-			}
-		}
-		if all_good {
-			total_count += 1
-		}
-	}
-
-	// Check Down
-	if row_idx > b.height-4 {
-		// Don't look down
-	} else {
-		all_good := true
-		for i, letter := range to_find {
-			li := idx + (b.width * i)
-			if b.text[li] != letter {
-				all_good = false
-				break
-			}
-		}
-		if all_good {
-			total_count += 1
-		}
-	}
-
-	// Check Left
-	if col_idx < 3 {
-		// Don't look left
-	} else {
-		all_good := true
-		for i, letter := range to_find {
-			li := idx - i
-			if b.text[li] != letter {
-				all_good = false
-				break
-			}
-		}
-		if all_good {
-			total_count += 1
-		}
-
-	}
-
-	// Check Right
-	if col_idx > b.width-4 {
-		// Don't look right
-	} else {
-		all_good := true
-		for i, letter := range to_find {
-			li := idx + i
-			if b.text[li] != letter {
-				all_good = false
-				break
-			}
-		}
-		if all_good {
-			total_count += 1
-		}
-	}
-
-	// Check Up-Left
-	if row_idx < 3 || col_idx < 3 {
-		// Don't look up-left
-	} else {
-		all_good := true
-		for i, letter := range to_find {
-			li := idx - (b.width * i) - i
-			if b.text[li] != letter {
-				all_good = false
-				break
-			}
-		}
-		if all_good {
-			total_count += 1
-		}
-	}
-
-	// Check Up-Right
-	if row_idx < 3 || col_idx > b.width-4 {
-		// Don't look up-right
-	} else {
-		all_good := true
-		for i, letter := range to_find {
-			li := idx - (b.width * i) + i
-			if b.text[li] != letter {
-				all_good = false
-				break
-			}
-		}
-		if all_good {
-			total_count += 1
-		}
-	}
-
-	// Check Down-Left
-	if row_idx > b.height-4 || col_idx < 3 {
-		// Don't look down-left
-	} else {
-		all_good := true
-		for i, letter := range to_find {
-			li := idx + (b.width * i) - i
-			if b.text[li] != letter {
-				all_good = false
-				break
-			}
-		}
-		if all_good {
-			total_count += 1
-		}
-	}
-
-	// Check Down-Right
-	if row_idx > b.height-4 || col_idx > b.width-4 {
-		// Don't look down-right
-	} else {
-		all_good := true
-		for i, letter := range to_find {
-			li := idx + (b.width * i) + i
-			if b.text[li] != letter {
-				all_good = false
-				break
-			}
-		}
-		if all_good {
+	for _, dir := range utils.Dirs8 {
+		if b.spells_from(row_idx, col_idx, dir.Y, dir.X, to_find) {
 			total_count += 1
 		}
 	}
@@ -190,7 +53,7 @@ func (b Board) CheckAllDirections(idx int, to_find []byte) int {
 func part1(board Board) int {
 	// Iterate over the board. If it is a "X", check all directions for "XMAS"
 	total_count := 0
-	for i, letter := range board.text {
+	for i, letter := range board.All() {
 		if letter == 'X' {
 			total_count += board.CheckAllDirections(i, XMAS[:])
 		}
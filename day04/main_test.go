@@ -26,34 +26,24 @@ MAMMMXMMMM
 MXMXAXMASX
 `
 
-func TestNewBoardSmall(t *testing.T) {
-	got := NewBoard(test_input_small)
-	want := Board{
-		text:   strings.ReplaceAll(test_input_small, "\n", ""),
-		width:  6,
-		height: 5,
-	}
-	assert.Equal(t, want, got)
-}
-
-func TestNewBoardLarge(t *testing.T) {
-	got := NewBoard(test_input_large)
-	want := Board{
-		text:   strings.ReplaceAll(test_input_large, "\n", ""),
-		width:  10,
-		height: 10,
+func TestNewBoard(t *testing.T) {
+	tests := []struct {
+		name          string
+		input         string
+		width, height int
+	}{
+		{"small", test_input_small, 6, 5},
+		{"large", test_input_large, 10, 10},
+		{"real", raw_text, 140, 140},
 	}
-	assert.Equal(t, want, got)
-}
-
-func TestNewBoardReal(t *testing.T) {
-	got := NewBoard(raw_text)
-	want := Board{
-		text:   strings.ReplaceAll(raw_text, "\n", ""),
-		width:  140,
-		height: 140,
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			got := NewBoard(tc.input)
+			assert.Equal(t, tc.width, got.Width)
+			assert.Equal(t, tc.height, got.Height)
+			assert.Equal(t, strings.ReplaceAll(tc.input, "\n", "")[:tc.width], string(got.Row(0)))
+		})
 	}
-	assert.Equal(t, want, got)
 }
 
 func TestCheckAllDirections(t *testing.T) {
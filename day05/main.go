@@ -36,14 +36,13 @@ func NewRules(input string) Rules {
 	}
 
 	// Split the input into rules and updates
-	parts := strings.Split(strings.TrimSpace(input), "\n\n")
-	if len(parts) != 2 {
-		panic("Found more than two parts when splitting by '\\n\\n'")
+	sections, err := utils.SectionsN(input, 2)
+	if err != nil {
+		panic(err)
 	}
 
 	// Parse the rules
-	rules := strings.SplitSeq(parts[0], "\n")
-	for rule := range rules {
+	for _, rule := range sections[0].Lines() {
 		// Split the rule into the before and after
 		parts := strings.Split(rule, "|")
 		if len(parts) != 2 {
@@ -62,8 +61,7 @@ func NewRules(input string) Rules {
 	}
 
 	// Parse the updates
-	updates := strings.SplitSeq(parts[1], "\n")
-	for update := range updates {
+	for _, update := range sections[1].Lines() {
 		// Split the update into the values
 		update_line := strings.Split(update, ",")
 		if len(update_line) == 0 {
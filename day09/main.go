@@ -6,6 +6,8 @@ import (
 	"slices"
 	"strings"
 	"time"
+
+	"github.com/natemcintosh/aoc_2024/utils"
 )
 
 // A compressed disk entry
@@ -147,7 +149,7 @@ func part2(compressed_disk []DiskEntry) int {
 	})
 
 	// Keep track of which file ids we've seen.
-	seen := make(map[int]struct{})
+	seen := utils.NewSet[int]()
 
 	// Iterate through the disk in reverse order
 	for right_idx := len(disk) - 1; right_idx >= 0; right_idx-- {
@@ -158,13 +160,13 @@ func part2(compressed_disk []DiskEntry) int {
 		if file_to_swap.file_id == -1 {
 			// fmt.Printf("Skipping empty file\n")
 			continue
-		} else if _, ok := seen[file_to_swap.file_id]; ok {
+		} else if seen.Contains(file_to_swap.file_id) {
 			// fmt.Printf("Skipping already seen file\n")
 			continue
 		} else {
 			// Add this file id to the ones we've seen
 			// fmt.Printf("Adding %+v to seen: %+v\n", file_to_swap.file_id, seen)
-			seen[file_to_swap.file_id] = struct{}{}
+			seen.Add(file_to_swap.file_id)
 		}
 
 		// For each index leading up to disk_entry_idx
@@ -224,6 +226,61 @@ func part2(compressed_disk []DiskEntry) int {
 
 }
 
+// part2_v2 gives the same answer as part2, but instead of scanning the disk from the
+// left for every file, it keeps a queue of free span start points for each span length.
+// Files and free spans are at most 9 blocks long, so finding the leftmost span that fits
+// a file only means peeking at the front of (at most) 9 queues.
+func part2_v2(compressed_disk []DiskEntry) int {
+	// free_spans[n] holds the start of each free span of length n, leftmost first
+	var free_spans [10]*utils.PriorityQueue[int]
+	for idx := range free_spans {
+		free_spans[idx] = utils.NewPriorityQueue(func(a, b int) bool { return a < b })
+	}
+
+	files := make([]DiskEntry, 0, len(compressed_disk))
+	for _, de := range compressed_disk {
+		if de.file_id == -1 {
+			free_spans[de.length].Push(de.start)
+		} else {
+			files = append(files, de)
+		}
+	}
+
+	// Sort the files so that the highest file ID is moved first
+	slices.SortFunc(files, func(a, b DiskEntry) int {
+		return b.file_id - a.file_id
+	})
+
+	sum := 0
+	for _, file := range files {
+		// Find the leftmost span that is big enough, and to the left of the file
+		best_length := -1
+		best_start := file.start
+		for length := file.length; length < len(free_spans); length++ {
+			if free_spans[length].Len() > 0 && free_spans[length].Peek() < best_start {
+				best_start = free_spans[length].Peek()
+				best_length = length
+			}
+		}
+
+		if best_length != -1 {
+			free_spans[best_length].Pop()
+
+			// Whatever is left over of the span is still free. The space the file
+			// leaves behind never needs to be tracked, because every file still to be
+			// moved is to the left of it.
+			if remaining_space := best_length - file.length; remaining_space > 0 {
+				free_spans[remaining_space].Push(best_start + file.length)
+			}
+			file.start = best_start
+		}
+
+		sum += file.CheckSum()
+	}
+
+	return sum
+}
+
 // The input text of the puzzle
 //
 //go:embed input.txt
@@ -243,7 +300,7 @@ func main() {
 
 	// === Part 2 ====================================================
 	p2_start := time.Now()
-	p2 := part2(compressed_disk)
+	p2 := part2_v2(compressed_disk)
 	p2_time := time.Since(p2_start)
 	fmt.Printf("Part 2: %v\n", p2)
 
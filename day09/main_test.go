@@ -92,6 +92,10 @@ func TestPart2(t *testing.T) {
 	got := part2(compressed_disk)
 	want := 2858
 	assert.Equal(t, want, got)
+
+	// Again, with part2_v2
+	got = part2_v2(compressed_disk)
+	assert.Equal(t, want, got)
 }
 
 func TestPart2Real(t *testing.T) {
@@ -99,4 +103,26 @@ func TestPart2Real(t *testing.T) {
 	got := part2(compressed_disk)
 	want := 6478232739671
 	assert.Equal(t, want, got)
+
+	// Again, with part2_v2
+	got = part2_v2(compressed_disk)
+	assert.Equal(t, want, got)
+}
+
+func BenchmarkPart2(b *testing.B) {
+	_, compressed_disk := create_disk(raw_text)
+	benchmarks := []struct {
+		name string
+		fn   func([]DiskEntry) int
+	}{
+		{"part2", part2},
+		{"part2_v2", part2_v2},
+	}
+	for _, bm := range benchmarks {
+		b.Run(bm.name, func(b *testing.B) {
+			for b.Loop() {
+				bm.fn(compressed_disk)
+			}
+		})
+	}
 }
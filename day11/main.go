@@ -3,29 +3,25 @@ package main
 import (
 	_ "embed"
 	"fmt"
-	"maps"
+	"slices"
 	"strconv"
-	"strings"
 	"time"
+
+	"github.com/natemcintosh/aoc_2024/utils"
 )
 
 func parse(raw_input string) []int {
-	var result []int
-	for _, v := range strings.Fields(strings.Trim(raw_input, "\n ")) {
-		num, _ := strconv.Atoi(v)
-		result = append(result, num)
-	}
-	return result
+	return utils.Ints(raw_input)
 }
 
 // Applies each rule in order, quiting if one matches. The `in_map` has the stone number
 // as the key, and the number of stones with that number as the value. The `out_map` is
-// the map that will be updated and returned.
-func update_stone(stone_val int, in_map, out_map map[int]int) map[int]int {
+// the counter that will be updated and returned.
+func update_stone(stone_val int, in_map, out_map utils.Counter[int]) utils.Counter[int] {
 	// Rule 1: if the value is 0, set it to 1
 	if stone_val == 0 {
 		// Get the number of stones with this value
-		out_map[1] += in_map[0]
+		out_map.Add(1, in_map[0])
 		return out_map
 	}
 
@@ -37,29 +33,24 @@ func update_stone(stone_val int, in_map, out_map map[int]int) map[int]int {
 		mid := len(s) / 2
 		first_half, _ := strconv.Atoi(s[:mid])
 		second_half, _ := strconv.Atoi(s[mid:])
-		out_map[first_half] += in_map[stone_val]
-		out_map[second_half] += in_map[stone_val]
+		out_map.Add(first_half, in_map[stone_val])
+		out_map.Add(second_half, in_map[stone_val])
 		return out_map
 	}
 
 	// Rule 3: if none of the above, multiply the number by 2024
-	out_map[stone_val*2024] += in_map[stone_val]
+	out_map.Add(stone_val*2024, in_map[stone_val])
 	return out_map
 }
 
 func solve(stones []int, n_steps int) int {
-	// Create a map for holding the number of stones at a given number
-	in_map := make(map[int]int, len(stones))
-
-	// Populate the map with the initial stones
-	for _, stone := range stones {
-		in_map[stone] += 1
-	}
+	// Count the number of stones at a given number
+	in_map := utils.CountAll(slices.Values(stones))
 
 	// Create the out_map
-	out_map := make(map[int]int)
+	out_map := utils.NewCounter[int](len(in_map))
 
-	// For n_steps, update all the stones in `sm`
+	// For n_steps, update all the stones in `in_map`
 	for range n_steps {
 		// Make sure we start with a fresh out_map each iteration
 		clear(out_map)
@@ -69,16 +60,13 @@ func solve(stones []int, n_steps int) int {
 			out_map = update_stone(stone_val, in_map, out_map)
 		}
 
-		// Overwrite in_map with out_map
-		in_map = maps.Clone(out_map)
+		// The stones in out_map are the input to the next step. Swap the two, so that
+		// the old in_map is re-used as the next out_map instead of allocating a new one.
+		in_map, out_map = out_map, in_map
 	}
 
 	// Count how many of each type of stone we have
-	sum := 0
-	for _, num := range in_map {
-		sum += num
-	}
-	return sum
+	return in_map.Total()
 }
 
 // The input text of the puzzle
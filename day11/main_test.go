@@ -3,6 +3,7 @@ package main
 import (
 	"testing"
 
+	"github.com/natemcintosh/aoc_2024/utils"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -10,8 +11,8 @@ func TestStep(t *testing.T) {
 	tests := []struct {
 		name   string
 		stone  int
-		in_map map[int]int
-		want   map[int]int
+		in_map utils.Counter[int]
+		want   utils.Counter[int]
 	}{
 		{"0", 0, map[int]int{0: 1}, map[int]int{1: 1}},
 		{"0", 0, map[int]int{0: 2}, map[int]int{1: 2}},
@@ -32,7 +33,7 @@ func TestStep(t *testing.T) {
 	}
 	for _, tc := range tests {
 		t.Run(tc.name, func(t *testing.T) {
-			got := update_stone(tc.stone, tc.in_map, make(map[int]int))
+			got := update_stone(tc.stone, tc.in_map, utils.NewCounter[int](0))
 			assert.Equal(t, tc.want, got)
 		})
 	}
@@ -72,3 +73,10 @@ func TestParts1And2(t *testing.T) {
 		})
 	}
 }
+
+func BenchmarkSolve(b *testing.B) {
+	stones := parse("6563348 67 395 0 6 4425 89567 739318")
+	for b.Loop() {
+		solve(stones, 75)
+	}
+}
@@ -4,24 +4,20 @@ import (
 	_ "embed"
 	"errors"
 	"fmt"
+	"math"
 	"regexp"
 	"strings"
 	"time"
 
 	"github.com/natemcintosh/aoc_2024/utils"
+	"github.com/natemcintosh/aoc_2024/utils/numth"
 )
 
-type Button struct {
-	Forward_x, Forward_y int
-}
-
-type Loc struct {
-	X, Y int
-}
-
+// ClawMachine has two buttons that each move the claw by a fixed amount, and a prize
+// that the claw must land on exactly.
 type ClawMachine struct {
-	ButtonA, ButtonB Button
-	Prize            Loc
+	ButtonA, ButtonB utils.Vec2
+	Prize            utils.Vec2
 }
 
 type MoveSolution struct {
@@ -30,13 +26,15 @@ type MoveSolution struct {
 
 var NotSolvableError = errors.New("machine is not solvable")
 
-// Cost returns the cost of winning at that machine. If no solution is found, it returns 0
+// Cost returns the cost of winning at that machine. If no solution is found, it returns 0.
+// If skip_over_100 is set, each button can be pushed at most 99 times.
 func (c ClawMachine) Cost(skip_over_100 bool) int {
-	sol, err := c.FindNumPushes()
-	if err != nil {
-		return 0
+	limit := 0
+	if skip_over_100 {
+		limit = 100
 	}
-	if (sol.NumPushesA >= 100 || sol.NumPushesB >= 100) && skip_over_100 {
+	sol, err := c.find_num_pushes(limit)
+	if err != nil {
 		return 0
 	}
 	return (sol.NumPushesA * 3) + sol.NumPushesB
@@ -45,64 +43,159 @@ func (c ClawMachine) Cost(skip_over_100 bool) int {
 // FindNumPushes returns the number of times each button was pushed.
 // If the machine is not solvable, it returns NotSolvableError.
 func (c ClawMachine) FindNumPushes() (MoveSolution, error) {
-	na_num := float64(-(c.ButtonB.Forward_x * c.Prize.Y) + c.Prize.X*c.ButtonB.Forward_y)
-	na_denom := float64(c.ButtonB.Forward_x*c.ButtonA.Forward_y - c.ButtonA.Forward_x*c.ButtonB.Forward_y)
+	return c.find_num_pushes(0)
+}
 
-	if na_denom == 0 {
+// under_limit says if both numbers of pushes are below `limit`. A limit of 0 or less
+// means there isn't one.
+func under_limit(sol MoveSolution, limit int) bool {
+	return limit <= 0 || (sol.NumPushesA < limit && sol.NumPushesB < limit)
+}
+
+// find_num_pushes is FindNumPushes where each button must be pushed fewer than `limit`
+// times. A limit of 0 or less means there isn't one.
+func (c ClawMachine) find_num_pushes(limit int) (MoveSolution, error) {
+	// The pushes na and nb must satisfy
+	//   na*A.X + nb*B.X = P.X
+	//   na*A.Y + nb*B.Y = P.Y
+	// This is solved exactly with integers. Floats lose precision once the prize is
+	// moved out past 1e13.
+	if c.ButtonA.Cross(c.ButtonB) == 0 {
+		return c.find_collinear_pushes(limit)
+	}
+	pushes, err := utils.SolveInt(
+		[][]int{
+			{c.ButtonA.X, c.ButtonB.X},
+			{c.ButtonA.Y, c.ButtonB.Y},
+		},
+		[]int{c.Prize.X, c.Prize.Y},
+	)
+	if err != nil {
 		return MoveSolution{}, NotSolvableError
 	}
 
-	na_res := -na_num / na_denom
-	if na_res != float64(int(na_res)) {
+	// Buttons can't be pushed a negative number of times
+	sol := MoveSolution{pushes[0], pushes[1]}
+	if sol.NumPushesA < 0 || sol.NumPushesB < 0 || !under_limit(sol, limit) {
 		return MoveSolution{}, NotSolvableError
 	}
+	return sol, nil
+}
 
-	nb_num := float64(c.ButtonA.Forward_x*c.Prize.Y - c.Prize.X*c.ButtonA.Forward_y)
-	nb_denom := float64(c.ButtonB.Forward_x*c.ButtonA.Forward_y - c.ButtonA.Forward_x*c.ButtonB.Forward_y)
-
-	if nb_denom == 0 {
+// find_collinear_pushes handles machines where both buttons move the claw along the same
+// line, so there can be many ways to reach the prize. It picks the cheapest one where
+// each button is pushed fewer than `limit` times, if there is a limit.
+func (c ClawMachine) find_collinear_pushes(limit int) (MoveSolution, error) {
+	A, B, P := c.ButtonA, c.ButtonB, c.Prize
+	zero := utils.Vec2{}
+
+	// Neither button moves the claw
+	if A == zero && B == zero {
+		if P == zero {
+			return MoveSolution{0, 0}, nil
+		}
 		return MoveSolution{}, NotSolvableError
 	}
 
-	nb_res := -nb_num / nb_denom
-	if nb_res != float64(int(nb_res)) {
+	// The prize has to be on the same line as the buttons
+	if A.Cross(P) != 0 || B.Cross(P) != 0 {
 		return MoveSolution{}, NotSolvableError
 	}
 
-	return MoveSolution{int(na_res), int(nb_res)}, nil
-}
-
-// parse_button_line takes a line like "Button A: X+94, Y+34" and returns a Button.
-// It uses regex to parse the line.
-func parse_button_line(line string) Button {
-	// The regex pattern
-	pattern := `Button [A-Z]: X\+(\d+), Y\+(\d+)$`
-	re := regexp.MustCompile(pattern)
+	// Everything is on one line through the origin, so only one coordinate is needed.
+	// Use X, unless the line is straight up and down.
+	alpha, beta, pi := A.X, B.X, P.X
+	if alpha == 0 && beta == 0 {
+		alpha, beta, pi = A.Y, B.Y, P.Y
+	}
 
-	// Find the groups
-	groups := utils.GetGroups(re, line)
+	// Only one button does anything
+	if alpha == 0 || beta == 0 {
+		step := alpha + beta
+		if pi%step != 0 || pi/step < 0 {
+			return MoveSolution{}, NotSolvableError
+		}
+		sol := MoveSolution{pi / step, 0}
+		if alpha == 0 {
+			sol = MoveSolution{0, pi / step}
+		}
+		if !under_limit(sol, limit) {
+			return MoveSolution{}, NotSolvableError
+		}
+		return sol, nil
+	}
 
-	// Parse the values
-	x := utils.ParseInt(groups[0][0])
-	y := utils.ParseInt(groups[0][1])
+	// Solve alpha*na + beta*nb = pi. One solution comes from extended Euclid, and all
+	// the others are
+	//   na = na0 + k*step_a
+	//   nb = nb0 + k*step_b
+	g, x, y := numth.ExtGCD(alpha, beta)
+	if pi%g != 0 {
+		return MoveSolution{}, NotSolvableError
+	}
+	na0, nb0 := x*(pi/g), y*(pi/g)
+	step_a, step_b := beta/g, -alpha/g
+
+	// Find the range of k where both numbers of pushes are at least 0, and below the
+	// limit if there is one. The steps are never 0, so each condition bounds k on one
+	// side.
+	lo, hi := math.MinInt, math.MaxInt
+	for _, bound := range [][2]int{{na0, step_a}, {nb0, step_b}} {
+		start, step := bound[0], bound[1]
+		if step > 0 {
+			lo = max(lo, numth.CeilDiv(-start, step))
+		} else {
+			hi = min(hi, numth.FloorDiv(start, -step))
+		}
+
+		// start + k*step <= limit-1
+		if limit > 0 {
+			if step > 0 {
+				hi = min(hi, numth.FloorDiv(limit-1-start, step))
+			} else {
+				lo = max(lo, numth.CeilDiv(start-(limit-1), -step))
+			}
+		}
+	}
+	if lo > hi {
+		return MoveSolution{}, NotSolvableError
+	}
 
-	return Button{x, y}
+	// The cost is a straight line in k, so the cheapest is at one end of the range.
+	// When it's not bounded on one side, both pushes grow that way, so that end can
+	// never be cheapest.
+	k := lo
+	if slope := 3*step_a + step_b; slope < 0 || lo == math.MinInt {
+		k = hi
+	}
+	return MoveSolution{na0 + k*step_a, nb0 + k*step_b}, nil
 }
 
-// parse_prize_line takes a line like "Prize: X=8400, Y=5400" and returns a Loc.
-func parse_prize_line(line string) Loc {
-	// The regex pattern
-	pattern := `Prize: X=(\d+), Y=(\d+)$`
-	re := regexp.MustCompile(pattern)
+// button_re matches a line like "Button A: X+94, Y+34". The group names match the
+// fields of utils.Vec2.
+var button_re = regexp.MustCompile(`Button [A-Z]: X\+(?P<X>\d+), Y\+(?P<Y>\d+)$`)
 
-	// Find the groups
-	groups := utils.GetGroups(re, line)
+// prize_re matches a line like "Prize: X=8400, Y=5400"
+var prize_re = regexp.MustCompile(`Prize: X=(?P<X>\d+), Y=(?P<Y>\d+)$`)
 
-	// Parse the values
-	x := utils.ParseInt(groups[0][0])
-	y := utils.ParseInt(groups[0][1])
+// parse_button_line takes a line like "Button A: X+94, Y+34" and returns how far the
+// button moves the claw.
+func parse_button_line(line string) utils.Vec2 {
+	button, err := utils.ParseLine[utils.Vec2](button_re, line)
+	if err != nil {
+		panic(err)
+	}
+	return button
+}
 
-	return Loc{x, y}
+// parse_prize_line takes a line like "Prize: X=8400, Y=5400" and returns the location
+// of the prize.
+func parse_prize_line(line string) utils.Vec2 {
+	prize, err := utils.ParseLine[utils.Vec2](prize_re, line)
+	if err != nil {
+		panic(err)
+	}
+	return prize
 }
 
 // A single machine comes in the form:
@@ -128,11 +221,11 @@ func parseMachine(raw_machine string) ClawMachine {
 // Button B: X+67, Y+21
 // Prize: X=12748, Y=12176
 func parse(raw_text string) []ClawMachine {
-	raw_machines := strings.Split(strings.TrimSpace(raw_text), "\n\n")
+	raw_machines := utils.Sections(raw_text)
 
 	machines := make([]ClawMachine, len(raw_machines))
 	for i, raw_machine := range raw_machines {
-		machines[i] = parseMachine(raw_machine)
+		machines[i] = parseMachine(raw_machine.Text)
 	}
 
 	return machines
@@ -150,8 +243,7 @@ func part1(machines []ClawMachine) int {
 func part2(machines []ClawMachine) int {
 	sum := 0
 	for _, machine := range machines {
-		machine.Prize.X += 10000000000000
-		machine.Prize.Y += 10000000000000
+		machine.Prize = machine.Prize.Add(utils.Vec2{X: 10000000000000, Y: 10000000000000})
 		sum += machine.Cost(false)
 	}
 
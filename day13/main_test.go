@@ -3,6 +3,7 @@ package main
 import (
 	"testing"
 
+	"github.com/natemcintosh/aoc_2024/utils"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -10,13 +11,13 @@ func TestParseButtonLine(t *testing.T) {
 	tests := []struct {
 		name     string
 		line     string
-		expected Button
+		expected utils.Vec2
 	}{
-		{"one", "Button A: X+94, Y+34", Button{94, 34}},
-		{"two", "Button B: X+22, Y+67", Button{22, 67}},
-		{"three", "Button B: X+26, Y+47", Button{26, 47}},
-		{"four", "Button A: X+13, Y+56", Button{13, 56}},
-		{"five", "Button B: X+67, Y+31", Button{67, 31}},
+		{"one", "Button A: X+94, Y+34", utils.Vec2{X: 94, Y: 34}},
+		{"two", "Button B: X+22, Y+67", utils.Vec2{X: 22, Y: 67}},
+		{"three", "Button B: X+26, Y+47", utils.Vec2{X: 26, Y: 47}},
+		{"four", "Button A: X+13, Y+56", utils.Vec2{X: 13, Y: 56}},
+		{"five", "Button B: X+67, Y+31", utils.Vec2{X: 67, Y: 31}},
 	}
 	for _, tc := range tests {
 		t.Run(tc.name, func(t *testing.T) {
@@ -30,13 +31,13 @@ func TestParsePrizeLine(t *testing.T) {
 	tests := []struct {
 		name     string
 		line     string
-		expected Loc
+		expected utils.Vec2
 	}{
-		{"one", "Prize: X=8400, Y=5400", Loc{8400, 5400}},
-		{"two", "Prize: X=12748, Y=12176", Loc{12748, 12176}},
-		{"three", "Prize: X=7870, Y=6450", Loc{7870, 6450}},
-		{"four", "Prize: X=18641, Y=10279", Loc{18641, 10279}},
-		{"five", "Prize: X=10843, Y=10358", Loc{10843, 10358}},
+		{"one", "Prize: X=8400, Y=5400", utils.Vec2{X: 8400, Y: 5400}},
+		{"two", "Prize: X=12748, Y=12176", utils.Vec2{X: 12748, Y: 12176}},
+		{"three", "Prize: X=7870, Y=6450", utils.Vec2{X: 7870, Y: 6450}},
+		{"four", "Prize: X=18641, Y=10279", utils.Vec2{X: 18641, Y: 10279}},
+		{"five", "Prize: X=10843, Y=10358", utils.Vec2{X: 10843, Y: 10358}},
 	}
 	for _, tc := range tests {
 		t.Run(tc.name, func(t *testing.T) {
@@ -53,29 +54,29 @@ func TestParseMachine(t *testing.T) {
 		expected    ClawMachine
 	}{
 		{"one", "Button A: X+94, Y+34\nButton B: X+22, Y+67\nPrize: X=8400, Y=5400", ClawMachine{
-			ButtonA: Button{94, 34},
-			ButtonB: Button{22, 67},
-			Prize:   Loc{8400, 5400},
+			ButtonA: utils.Vec2{X: 94, Y: 34},
+			ButtonB: utils.Vec2{X: 22, Y: 67},
+			Prize:   utils.Vec2{X: 8400, Y: 5400},
 		}},
 		{"two", "Button A: X+26, Y+66\nButton B: X+67, Y+21\nPrize: X=12748, Y=12176", ClawMachine{
-			ButtonA: Button{26, 66},
-			ButtonB: Button{67, 21},
-			Prize:   Loc{12748, 12176},
+			ButtonA: utils.Vec2{X: 26, Y: 66},
+			ButtonB: utils.Vec2{X: 67, Y: 21},
+			Prize:   utils.Vec2{X: 12748, Y: 12176},
 		}},
 		{"three", "Button A: X+17, Y+86\nButton B: X+84, Y+37\nPrize: X=7870, Y=6450", ClawMachine{
-			ButtonA: Button{17, 86},
-			ButtonB: Button{84, 37},
-			Prize:   Loc{7870, 6450},
+			ButtonA: utils.Vec2{X: 17, Y: 86},
+			ButtonB: utils.Vec2{X: 84, Y: 37},
+			Prize:   utils.Vec2{X: 7870, Y: 6450},
 		}},
 		{"four", "Button A: X+69, Y+23\nButton B: X+27, Y+71\nPrize: X=18641, Y=10279", ClawMachine{
-			ButtonA: Button{69, 23},
-			ButtonB: Button{27, 71},
-			Prize:   Loc{18641, 10279},
+			ButtonA: utils.Vec2{X: 69, Y: 23},
+			ButtonB: utils.Vec2{X: 27, Y: 71},
+			Prize:   utils.Vec2{X: 18641, Y: 10279},
 		}},
 		{"five", "Button A: X+13, Y+56\nButton B: X+67, Y+31\nPrize: X=10843, Y=10358", ClawMachine{
-			ButtonA: Button{13, 56},
-			ButtonB: Button{67, 31},
-			Prize:   Loc{10843, 10358},
+			ButtonA: utils.Vec2{X: 13, Y: 56},
+			ButtonB: utils.Vec2{X: 67, Y: 31},
+			Prize:   utils.Vec2{X: 10843, Y: 10358},
 		}},
 	}
 	for _, tc := range tests {
@@ -94,25 +95,38 @@ func TestFindNumPushes(t *testing.T) {
 		expected_err error
 	}{
 		{"one", ClawMachine{
-			ButtonA: Button{94, 34},
-			ButtonB: Button{22, 67},
-			Prize:   Loc{8400, 5400},
+			ButtonA: utils.Vec2{X: 94, Y: 34},
+			ButtonB: utils.Vec2{X: 22, Y: 67},
+			Prize:   utils.Vec2{X: 8400, Y: 5400},
 		}, MoveSolution{80, 40}, nil},
 		{"two", ClawMachine{
-			ButtonA: Button{26, 66},
-			ButtonB: Button{67, 21},
-			Prize:   Loc{12748, 12176},
+			ButtonA: utils.Vec2{X: 26, Y: 66},
+			ButtonB: utils.Vec2{X: 67, Y: 21},
+			Prize:   utils.Vec2{X: 12748, Y: 12176},
 		}, MoveSolution{}, NotSolvableError},
 		{"three", ClawMachine{
-			ButtonA: Button{17, 86},
-			ButtonB: Button{84, 37},
-			Prize:   Loc{7870, 6450},
+			ButtonA: utils.Vec2{X: 17, Y: 86},
+			ButtonB: utils.Vec2{X: 84, Y: 37},
+			Prize:   utils.Vec2{X: 7870, Y: 6450},
 		}, MoveSolution{38, 86}, nil},
 		{"four", ClawMachine{
-			ButtonA: Button{69, 23},
-			ButtonB: Button{27, 71},
-			Prize:   Loc{18641, 10279},
+			ButtonA: utils.Vec2{X: 69, Y: 23},
+			ButtonB: utils.Vec2{X: 27, Y: 71},
+			Prize:   utils.Vec2{X: 18641, Y: 10279},
 		}, MoveSolution{}, NotSolvableError},
+		{"negative", ClawMachine{
+			ButtonA: utils.Vec2{X: 1, Y: 0},
+			ButtonB: utils.Vec2{X: 0, Y: 1},
+			Prize:   utils.Vec2{X: -3, Y: 4},
+		}, MoveSolution{}, NotSolvableError},
+		{"bigger than float64 can hold exactly", ClawMachine{
+			ButtonA: utils.Vec2{X: 94, Y: 34},
+			ButtonB: utils.Vec2{X: 22, Y: 67},
+			Prize: utils.Vec2{
+				X: 94*(1e15+7) + 22*(3e15+1),
+				Y: 34*(1e15+7) + 67*(3e15+1),
+			},
+		}, MoveSolution{1e15 + 7, 3e15 + 1}, nil},
 	}
 	for _, tc := range tests {
 		t.Run(tc.name, func(t *testing.T) {
@@ -136,3 +150,108 @@ func TestPart2Real(t *testing.T) {
 	got := part2(machines)
 	assert.Equal(t, want, got)
 }
+
+func TestFindNumPushesCollinear(t *testing.T) {
+	tests := []struct {
+		name         string
+		a, b, prize  utils.Vec2
+		expected_sol MoveSolution
+		expected_err error
+	}{
+		// Parallel buttons
+		{"parallel, B cheaper", utils.Vec2{X: 2, Y: 4}, utils.Vec2{X: 3, Y: 6}, utils.Vec2{X: 12, Y: 24}, MoveSolution{0, 4}, nil},
+		{"parallel, A cheaper", utils.Vec2{X: 6, Y: 6}, utils.Vec2{X: 1, Y: 1}, utils.Vec2{X: 13, Y: 13}, MoveSolution{2, 1}, nil},
+		{"parallel, vertical", utils.Vec2{X: 0, Y: 4}, utils.Vec2{X: 0, Y: 6}, utils.Vec2{X: 0, Y: 14}, MoveSolution{2, 1}, nil},
+		{"parallel, prize behind", utils.Vec2{X: 1, Y: 1}, utils.Vec2{X: 2, Y: 2}, utils.Vec2{X: -3, Y: -3}, MoveSolution{}, NotSolvableError},
+		{"parallel, prize off the line", utils.Vec2{X: 1, Y: 1}, utils.Vec2{X: 2, Y: 2}, utils.Vec2{X: 3, Y: 4}, MoveSolution{}, NotSolvableError},
+		{"parallel, no integer solution", utils.Vec2{X: 2, Y: 2}, utils.Vec2{X: 4, Y: 4}, utils.Vec2{X: 3, Y: 3}, MoveSolution{}, NotSolvableError},
+		{"parallel, far away", utils.Vec2{X: 3, Y: 5}, utils.Vec2{X: 6, Y: 10}, utils.Vec2{X: 3e13, Y: 5e13}, MoveSolution{0, 5e12}, nil},
+
+		// Anti-parallel buttons, so the pushes can cancel each other out
+		{"anti-parallel", utils.Vec2{X: 2, Y: 2}, utils.Vec2{X: -1, Y: -1}, utils.Vec2{X: 3, Y: 3}, MoveSolution{2, 1}, nil},
+		{"anti-parallel, prize behind", utils.Vec2{X: 2, Y: 2}, utils.Vec2{X: -1, Y: -1}, utils.Vec2{X: -3, Y: -3}, MoveSolution{0, 3}, nil},
+		{"anti-parallel, at the start", utils.Vec2{X: 3, Y: 1}, utils.Vec2{X: -6, Y: -2}, utils.Vec2{}, MoveSolution{0, 0}, nil},
+		{"anti-parallel, no integer solution", utils.Vec2{X: 4, Y: 2}, utils.Vec2{X: -6, Y: -3}, utils.Vec2{X: 3, Y: 1}, MoveSolution{}, NotSolvableError},
+
+		// Buttons that don't move the claw
+		{"A does nothing", utils.Vec2{}, utils.Vec2{X: 2, Y: 3}, utils.Vec2{X: 4, Y: 6}, MoveSolution{0, 2}, nil},
+		{"B does nothing", utils.Vec2{X: 2, Y: 3}, utils.Vec2{}, utils.Vec2{X: 4, Y: 6}, MoveSolution{2, 0}, nil},
+		{"A does nothing, prize behind", utils.Vec2{}, utils.Vec2{X: 2, Y: 3}, utils.Vec2{X: -4, Y: -6}, MoveSolution{}, NotSolvableError},
+		{"A does nothing, can't reach", utils.Vec2{}, utils.Vec2{X: 2, Y: 3}, utils.Vec2{X: 1, Y: 1}, MoveSolution{}, NotSolvableError},
+		{"neither does anything", utils.Vec2{}, utils.Vec2{}, utils.Vec2{X: 1, Y: 1}, MoveSolution{}, NotSolvableError},
+		{"neither does anything, at the prize", utils.Vec2{}, utils.Vec2{}, utils.Vec2{}, MoveSolution{0, 0}, nil},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			claw := ClawMachine{ButtonA: tc.a, ButtonB: tc.b, Prize: tc.prize}
+			solution, err := claw.FindNumPushes()
+			assert.Equal(t, tc.expected_sol, solution)
+			assert.Equal(t, tc.expected_err, err)
+		})
+	}
+
+	// With a limit on pushes, the cheapest solution may be over it while another isn't
+	limited := []struct {
+		name         string
+		a, b, prize  utils.Vec2
+		expected_sol MoveSolution
+		expected_err error
+	}{
+		{"cheapest is over the limit", utils.Vec2{X: 1, Y: 1}, utils.Vec2{X: 3, Y: 3}, utils.Vec2{X: 300, Y: 300}, MoveSolution{3, 99}, nil},
+		{"cheapest is under the limit", utils.Vec2{X: 2, Y: 4}, utils.Vec2{X: 3, Y: 6}, utils.Vec2{X: 12, Y: 24}, MoveSolution{0, 4}, nil},
+		{"every solution is over the limit", utils.Vec2{X: 1, Y: 1}, utils.Vec2{X: 2, Y: 2}, utils.Vec2{X: 400, Y: 400}, MoveSolution{}, NotSolvableError},
+		{"only B, over the limit", utils.Vec2{}, utils.Vec2{X: 1, Y: 1}, utils.Vec2{X: 100, Y: 100}, MoveSolution{}, NotSolvableError},
+		{"only B, under the limit", utils.Vec2{}, utils.Vec2{X: 1, Y: 1}, utils.Vec2{X: 99, Y: 99}, MoveSolution{0, 99}, nil},
+	}
+	for _, tc := range limited {
+		t.Run(tc.name, func(t *testing.T) {
+			claw := ClawMachine{ButtonA: tc.a, ButtonB: tc.b, Prize: tc.prize}
+			solution, err := claw.find_num_pushes(100)
+			assert.Equal(t, tc.expected_sol, solution)
+			assert.Equal(t, tc.expected_err, err)
+		})
+	}
+
+	claw := ClawMachine{ButtonA: utils.Vec2{X: 1, Y: 1}, ButtonB: utils.Vec2{X: 3, Y: 3}, Prize: utils.Vec2{X: 300, Y: 300}}
+	assert.Equal(t, 108, claw.Cost(true))
+	assert.Equal(t, 100, claw.Cost(false))
+}
+
+// TestFindNumPushesCollinearBruteForce checks the cheapest solution against trying
+// every number of pushes
+func TestFindNumPushesCollinearBruteForce(t *testing.T) {
+	for _, a := range []int{-4, -3, -1, 0, 1, 2, 6} {
+		for _, b := range []int{-5, -2, 0, 1, 3, 4} {
+			for p := -12; p <= 12; p++ {
+				claw := ClawMachine{
+					ButtonA: utils.Vec2{X: a, Y: 2 * a},
+					ButtonB: utils.Vec2{X: b, Y: 2 * b},
+					Prize:   utils.Vec2{X: p, Y: 2 * p},
+				}
+
+				// With no limit, then with each button pushed fewer than 5 times
+				for _, limit := range []int{0, 5} {
+					best, found := MoveSolution{}, false
+					for na := range 40 {
+						for nb := range 40 {
+							if na*a+nb*b != p || !under_limit(MoveSolution{na, nb}, limit) {
+								continue
+							}
+							if !found || 3*na+nb < 3*best.NumPushesA+best.NumPushesB {
+								best, found = MoveSolution{na, nb}, true
+							}
+						}
+					}
+
+					solution, err := claw.find_num_pushes(limit)
+					if !found {
+						assert.Error(t, err, "a=%d b=%d p=%d limit=%d", a, b, p, limit)
+						continue
+					}
+					assert.NoError(t, err, "a=%d b=%d p=%d limit=%d", a, b, p, limit)
+					assert.Equal(t, 3*best.NumPushesA+best.NumPushesB, 3*solution.NumPushesA+solution.NumPushesB, "a=%d b=%d p=%d limit=%d", a, b, p, limit)
+				}
+			}
+		}
+	}
+}
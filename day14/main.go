@@ -3,31 +3,46 @@ package main
 import (
 	_ "embed"
 	"fmt"
+	"hash/maphash"
+	"math"
 	"regexp"
 	"slices"
 	"time"
 
 	"github.com/natemcintosh/aoc_2024/utils"
+	"github.com/natemcintosh/aoc_2024/utils/numth"
 )
 
+// Robot has a position on the board, and moves by its velocity each step
 type Robot struct {
-	x, y   int
-	vx, vy int
+	pos, vel utils.Vec2
 }
 
+func new_robot(x, y, vx, vy int) Robot {
+	return Robot{utils.Vec2{X: x, Y: y}, utils.Vec2{X: vx, Y: vy}}
+}
+
+// robot_line is a single line of the input, before it is turned into a Robot
+type robot_line struct {
+	X, Y   int
+	VX, VY int
+}
+
+var robot_re = regexp.MustCompile(`p=(?P<X>-?\d+),(?P<Y>-?\d+)\sv=(?P<VX>-?\d+),(?P<VY>-?\d+)`)
+
 // parse_robots takes in multiple lines that looks like
 // p=0,4 v=3,-3
 // p=10,3 v=-1,2
 // And returns []Robot.
 func parse_robots(raw_text string) []Robot {
-	re := regexp.MustCompile(`p=(-?\d+),(-?\d+)\sv=(-?\d+),(-?\d+)`)
-	matches := utils.GetGroups(re, raw_text)
-
-	robots := make([]Robot, len(matches))
-	for i, m := range matches {
-		x, y := utils.ParseInt(m[0]), utils.ParseInt(m[1])
-		vx, vy := utils.ParseInt(m[2]), utils.ParseInt(m[3])
-		robots[i] = Robot{x, y, vx, vy}
+	lines, err := utils.ParseInto[robot_line](robot_re, raw_text)
+	if err != nil {
+		panic(err)
+	}
+
+	robots := make([]Robot, len(lines))
+	for i, l := range lines {
+		robots[i] = new_robot(l.X, l.Y, l.VX, l.VY)
 	}
 
 	return robots
@@ -35,27 +50,11 @@ func parse_robots(raw_text string) []Robot {
 
 // PropNSteps will move the robot n steps in the direction of its velocity.
 // When it goes over the edge of the map, it will wrap around.
+// In this case, a positive y velocity means the robot is moving down, and vice versa.
 func (r Robot) PropNSteps(n, board_x, board_y int) Robot {
-	new_x := (r.x + (n * r.vx)) % board_x
-	if new_x < 0 {
-		new_x = board_x + new_x
-	} else {
-		new_x = new_x % board_x
-	}
-
-	// In this case, a positive number means the robot is moving down, and vice versa.
-	new_y := (r.y + (n * r.vy)) % board_y
-	if new_y < 0 {
-		new_y = board_y + new_y
-	} else {
-		new_y = new_y % board_y
-	}
-
 	return Robot{
-		x:  new_x,
-		y:  new_y,
-		vx: r.vx,
-		vy: r.vy,
+		pos: r.pos.Add(r.vel.Scale(n)).WrapMod(board_x, board_y),
+		vel: r.vel,
 	}
 }
 
@@ -74,16 +73,16 @@ func CalcSafetyFactor(robots []Robot, n_steps, board_x, board_y int) int {
 
 	for _, r := range rbs {
 		// If the robot is on one of the middle lines, ignore it.
-		if r.x == mid_x || r.y == mid_y {
+		if r.pos.X == mid_x || r.pos.Y == mid_y {
 			continue
 		}
 
 		// Figure out which quadrant it is in.
-		if (r.x < mid_x) && (r.y < mid_y) {
+		if (r.pos.X < mid_x) && (r.pos.Y < mid_y) {
 			quadrants[0] += 1
-		} else if (r.x > mid_x) && (r.y < mid_y) {
+		} else if (r.pos.X > mid_x) && (r.pos.Y < mid_y) {
 			quadrants[1] += 1
-		} else if (r.x > mid_x) && (r.y > mid_y) {
+		} else if (r.pos.X > mid_x) && (r.pos.Y > mid_y) {
 			quadrants[2] += 1
 		} else {
 			quadrants[3] += 1
@@ -95,25 +94,18 @@ func CalcSafetyFactor(robots []Robot, n_steps, board_x, board_y int) int {
 }
 
 func PrintBoard(robots []Robot, board_x, board_y int) {
-	board := make([][]int, board_y)
-	for i := range board {
-		board[i] = make([]int, board_x)
-	}
+	board := utils.NewGrid[int](board_x, board_y)
 
 	for _, r := range robots {
-		board[r.y][r.x] += 1
+		board.Set(r.pos.Y, r.pos.X, board.At(r.pos.Y, r.pos.X)+1)
 	}
 
-	for _, row := range board {
-		for _, cell := range row {
-			if cell > 0 {
-				fmt.Printf("#")
-			} else {
-				fmt.Printf(".")
-			}
+	fmt.Print(board.Sprint(func(cell int) string {
+		if cell > 0 {
+			return "#"
 		}
-		fmt.Printf("\n")
-	}
+		return "."
+	}))
 }
 
 // longest_nonzero_consecutive finds the longest consective non-zero sequence of
@@ -152,27 +144,22 @@ func longest_nonzero_consecutive(arr []int) int {
 }
 
 func part2(robots []Robot, board_x, board_y, max_iters int) int {
-	// Make a 2D slice for the board.
-	arr := make([][]int, board_y)
-	for i := range arr {
-		arr[i] = make([]int, board_x)
-	}
+	// Make a grid for the board.
+	arr := utils.NewGrid[int](board_x, board_y)
 
 	for step_n := range max_iters {
 		// Make sure we start with an empty map.
-		for _, row := range arr {
-			clear(row)
-		}
+		arr.Fill(0)
 
 		// Propagate all the robots one step
 		for j, r := range robots {
 			nr := r.PropNSteps(1, board_x, board_y)
 			robots[j] = nr
-			arr[nr.y][nr.x] += 1
+			arr.Set(nr.pos.Y, nr.pos.X, arr.At(nr.pos.Y, nr.pos.X)+1)
 		}
 
 		// Look for 10+ consecutive columns with at least one robot in each.
-		for _, row := range arr {
+		for _, row := range arr.Rows() {
 			if longest_nonzero_consecutive(row) >= 10 {
 				// PrintBoard(robots, board_x, board_y)
 				return step_n + 1
@@ -182,6 +169,79 @@ func part2(robots []Robot, board_x, board_y, max_iters int) int {
 	return -1
 }
 
+// spread returns n times the variance of the values, which is enough to compare how
+// spread out two sets of the same size are
+func spread(vals []int) int {
+	sum, sum_sq := 0, 0
+	for _, v := range vals {
+		sum += v
+		sum_sq += v * v
+	}
+	return len(vals)*sum_sq - sum*sum
+}
+
+// part2_crt finds the tree without stepping through every picture. The x positions
+// repeat every board_x steps, and the y positions every board_y steps. When the tree
+// shows up, the robots bunch together, so the x positions are least spread out at some
+// step tx (mod board_x), and the y positions at some step ty (mod board_y). The Chinese
+// remainder theorem puts them together into a single step.
+func part2_crt(robots []Robot, board_x, board_y int) int {
+	// best_step returns the step in [0, period) where the positions along one axis are
+	// least spread out
+	best_step := func(period int, pos_vel func(Robot) (int, int)) int {
+		vals := make([]int, len(robots))
+		best, best_spread := 0, math.MaxInt
+		for step := range period {
+			for i, r := range robots {
+				p, v := pos_vel(r)
+				vals[i] = utils.Mod(p+v*step, period)
+			}
+			if s := spread(vals); s < best_spread {
+				best, best_spread = step, s
+			}
+		}
+		return best
+	}
+
+	tx := best_step(board_x, func(r Robot) (int, int) { return r.pos.X, r.vel.X })
+	ty := best_step(board_y, func(r Robot) (int, int) { return r.pos.Y, r.vel.Y })
+
+	step, _, err := numth.CRT([]int{tx, ty}, []int{board_x, board_y})
+	if err != nil {
+		return -1
+	}
+	return step
+}
+
+// step_robots moves every robot one step, without changing the input
+func step_robots(robots []Robot, board_x, board_y int) []Robot {
+	res := make([]Robot, len(robots))
+	for i, r := range robots {
+		res[i] = r.PropNSteps(1, board_x, board_y)
+	}
+	return res
+}
+
+// robots_seed makes robots_key give the same hash for the same board within a run
+var robots_seed = maphash.MakeSeed()
+
+// robots_key hashes where all of the robots are, so that boards can be compared when
+// looking for a cycle
+func robots_key(robots []Robot) uint64 {
+	var h maphash.Hash
+	h.SetSeed(robots_seed)
+	for _, r := range robots {
+		maphash.WriteComparable(&h, r.pos)
+	}
+	return h.Sum64()
+}
+
+// robots_cycle finds how many steps it takes for the whole board to repeat
+func robots_cycle(robots []Robot, board_x, board_y int) utils.Cycle {
+	next := func(rbs []Robot) []Robot { return step_robots(rbs, board_x, board_y) }
+	return utils.BrentFunc(robots, next, robots_key)
+}
+
 // The input text of the puzzle
 //
 //go:embed input.txt
@@ -201,7 +261,7 @@ func main() {
 
 	// === Part 2 ====================================================
 	p2_start := time.Now()
-	p2 := part2(machines, 101, 103, 100000)
+	p2 := part2_crt(machines, 101, 103)
 	p2_time := time.Since(p2_start)
 	fmt.Printf("Part 2: %v\n", p2)
 
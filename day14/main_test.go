@@ -1,8 +1,10 @@
 package main
 
 import (
+	"slices"
 	"testing"
 
+	"github.com/natemcintosh/aoc_2024/utils"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -21,36 +23,36 @@ p=9,5 v=-3,-3`
 
 func TestParseRobots(t *testing.T) {
 	want := []Robot{
-		{0, 4, 3, -3},
-		{6, 3, -1, -3},
-		{10, 3, -1, 2},
-		{2, 0, 2, -1},
-		{0, 0, 1, 3},
-		{3, 0, -2, -2},
-		{7, 6, -1, -3},
-		{3, 0, -1, -2},
-		{9, 3, 2, 3},
-		{7, 3, -1, 2},
-		{2, 4, 2, -3},
-		{9, 5, -3, -3},
+		new_robot(0, 4, 3, -3),
+		new_robot(6, 3, -1, -3),
+		new_robot(10, 3, -1, 2),
+		new_robot(2, 0, 2, -1),
+		new_robot(0, 0, 1, 3),
+		new_robot(3, 0, -2, -2),
+		new_robot(7, 6, -1, -3),
+		new_robot(3, 0, -1, -2),
+		new_robot(9, 3, 2, 3),
+		new_robot(7, 3, -1, 2),
+		new_robot(2, 4, 2, -3),
+		new_robot(9, 5, -3, -3),
 	}
 	got := parse_robots(test_input)
 	assert.Equal(t, want, got)
 }
 
 func TestPropNSteps(t *testing.T) {
-	r := Robot{2, 4, 2, -3}
+	r := new_robot(2, 4, 2, -3)
 
 	tests := []struct {
 		name string
 		n    int
 		want Robot
 	}{
-		{"1 steps", 1, Robot{4, 1, 2, -3}},
-		{"2 steps", 2, Robot{6, 5, 2, -3}},
-		{"3 steps", 3, Robot{8, 2, 2, -3}},
-		{"4 steps", 4, Robot{10, 6, 2, -3}},
-		{"5 steps", 5, Robot{1, 3, 2, -3}},
+		{"1 steps", 1, new_robot(4, 1, 2, -3)},
+		{"2 steps", 2, new_robot(6, 5, 2, -3)},
+		{"3 steps", 3, new_robot(8, 2, 2, -3)},
+		{"4 steps", 4, new_robot(10, 6, 2, -3)},
+		{"5 steps", 5, new_robot(1, 3, 2, -3)},
 	}
 
 	for _, tc := range tests {
@@ -101,3 +103,44 @@ func TestPart2Real(t *testing.T) {
 	want := 8258
 	assert.Equal(t, want, got)
 }
+
+func TestPart2CRT(t *testing.T) {
+	robots := parse_robots(raw_text)
+	got := part2_crt(robots, 101, 103)
+	want := 8258
+	assert.Equal(t, want, got)
+
+	// Should agree with stepping through every picture
+	assert.Equal(t, part2(robots, 101, 103, 10000), got)
+}
+
+func BenchmarkPart2(b *testing.B) {
+	robots := parse_robots(raw_text)
+	for b.Loop() {
+		part2(slices.Clone(robots), 101, 103, 10000)
+	}
+}
+
+func BenchmarkPart2CRT(b *testing.B) {
+	robots := parse_robots(raw_text)
+	for b.Loop() {
+		part2_crt(robots, 101, 103)
+	}
+}
+
+func TestRobotsCycle(t *testing.T) {
+	robots := parse_robots(raw_text)
+
+	// Each robot comes back to where it started every 101*103 steps, and the board as a
+	// whole doesn't repeat any sooner
+	c := robots_cycle(robots, 101, 103)
+	assert.Equal(t, utils.Cycle{Tail: 0, Period: 101 * 103}, c)
+
+	// Fast-forwarding a trillion steps only takes a few thousand
+	n := int(1e12)
+	next := func(rbs []Robot) []Robot { return step_robots(rbs, 101, 103) }
+	got := utils.FastForward(robots, next, c, n)
+	for i, r := range robots {
+		assert.Equal(t, r.PropNSteps(n, 101, 103), got[i])
+	}
+}
@@ -3,158 +3,388 @@ package main
 import (
 	_ "embed"
 	"fmt"
-	"maps"
+	"io"
+	"iter"
+	"math"
+	"math/big"
+	"os"
 	"slices"
+	"strconv"
 	"strings"
 	"time"
+
+	"github.com/natemcintosh/aoc_2024/utils"
+	"github.com/natemcintosh/aoc_2024/utils/numth"
 )
 
 // parse_towels takes in the raw input string and returns the list of building blocks
 // and the list of desired patterns
 func parse_towels(raw string) ([]string, []string) {
 	// Split around a blank line
-	raw_splits := strings.SplitN(raw, "\n\n", 2)
-	raw_bb := raw_splits[0]
-	raw_desired := raw_splits[1]
+	sections, err := utils.SectionsN(raw, 2)
+	if err != nil {
+		panic(err)
+	}
 
 	// Split the building blocks into a slice, splitting on ", "
-	// and trimming the trailing newline
-	building_blocks := strings.Split(strings.TrimSpace(raw_bb), ", ")
+	building_blocks := strings.Split(sections[0].Text, ", ")
 
-	// Split the desired patterns into a slice, splitting on newlines
-	// and trimming the trailing newline
-	desired_patterns := strings.Split(strings.TrimSpace(raw_desired), "\n")
+	// Each line of the second section is a desired pattern
+	desired_patterns := sections[1].Lines()
 
 	return building_blocks, desired_patterns
 }
 
-var NoMatchFound = fmt.Errorf("no match found")
-
-// inner_find_matches takes in a string and a list of patterns and sees which of the building_blocks
-// fit at the start of rem. Then it recursively calls itself with the remaining string and the
-// remaining patterns.
+// TowelTrie is a trie of the towel patterns. Walking it along a design finds every towel
+// that fits at the start of the design in one pass, instead of checking each towel.
 //
-// If it find a complete match, it adds that to the count of ways the string can be made
-func inner_find_matches(rem string, building_blocks []string, n_matches int, n_ways map[string]int) int {
-	// If we've already calculated the number of ways to build rem, return it
-	if n, ok := n_ways[rem]; ok {
-		return n
-	}
+// It is not suggested to create this struct directly. Instead, always use NewTowelTrie
+type TowelTrie struct {
+	// next[n][c] is the node reached from node n by the letter 'a'+c, or 0 if there
+	// isn't one. Node 0 is the root, which can never be reached from another node.
+	next [][26]int32
 
-	// Keep track of the number of ways we can build rem from smaller strings
-	n_ways_rem := 0
+	// is_towel[n] says if the letters on the way to node n spell out a towel
+	is_towel []bool
+}
 
-	// For each match, run find_matches on the remaining string all the
-	// available_patterns, passing in the ones matched so far
-	for _, m := range building_blocks {
-		// If this is not the start of rem, then continue
-		if !strings.HasPrefix(rem, m) {
-			continue
+// NewTowelTrie builds a trie from the towels. Towels must be made of lowercase letters.
+func NewTowelTrie(towels []string) *TowelTrie {
+	t := &TowelTrie{next: make([][26]int32, 1), is_towel: make([]bool, 1)}
+	for _, towel := range towels {
+		node := int32(0)
+		for _, c := range []byte(towel) {
+			if c < 'a' || c > 'z' {
+				panic(fmt.Sprintf("towel %q has a letter that isn't lowercase", towel))
+			}
+			if t.next[node][c-'a'] == 0 {
+				t.next[node][c-'a'] = int32(len(t.next))
+				t.next = append(t.next, [26]int32{})
+				t.is_towel = append(t.is_towel, false)
+			}
+			node = t.next[node][c-'a']
 		}
+		t.is_towel[node] = true
+	}
+	return t
+}
 
-		// If we've matched all the way to the end, return n_matches + 1
-		if len(rem) == len(m) {
-			n_matches += 1
-
-			// Add the number of ways we can build rem to the map
-			n_ways[rem] += 1
-			continue
+// towels_at yields the length of every towel that fits at the start of `s`, shortest
+// first. The walk stops once no towel is that long, or the letters stop matching, so it
+// takes at most as many steps as the longest towel.
+func (t *TowelTrie) towels_at(s string) iter.Seq[int] {
+	return func(yield func(int) bool) {
+		node := int32(0)
+		for i := range len(s) {
+			c := s[i]
+			if c < 'a' || c > 'z' {
+				return
+			}
+			node = t.next[node][c-'a']
+			if node == 0 {
+				return
+			}
+			if t.is_towel[node] && !yield(i+1) {
+				return
+			}
 		}
+	}
+}
 
-		// Call the recursive function
-		new_ways := inner_find_matches(rem[len(m):], building_blocks, n_matches, n_ways)
-		n_matches += new_ways
-		n_ways_rem += new_ways
+// CountWays returns the number of ways to build `design` from the towels. ways[i] is the
+// number of ways to build design[i:], which is the sum of the ways to build what's left
+// after each towel that fits at i. Working from the end back, that takes O(len(design) ×
+// longest towel) time.
+//
+// The number of ways can grow exponentially with the length of the design. If it is too
+// big for an int, CountWays returns an error wrapping numth.ErrOverflow. Use CountWaysBig
+// to count past that.
+func (t *TowelTrie) CountWays(design string) (int, error) {
+	ways := make([]int, len(design)+1)
+	ways[len(design)] = 1
+	for i := len(design) - 1; i >= 0; i-- {
+		for length := range t.towels_at(design[i:]) {
+			// Counts are never negative, so this is the only way to overflow
+			if ways[i] > math.MaxInt-ways[i+length] {
+				return 0, fmt.Errorf("counting ways to build %q: %w", design, numth.ErrOverflow)
+			}
+			ways[i] += ways[i+length]
+		}
 	}
+	return ways[0], nil
+}
 
-	// Add the number of ways we can build rem to the map
-	if n_ways_rem > 0 {
-		n_ways[rem] += n_ways_rem
+// CountWaysBig is CountWays for any number of ways. It counts with ints first, and only
+// switches to big.Int if that overflows.
+func (t *TowelTrie) CountWaysBig(design string) *big.Int {
+	if n, err := t.CountWays(design); err == nil {
+		return big.NewInt(int64(n))
 	}
 
-	return n_matches
+	ways := make([]*big.Int, len(design)+1)
+	ways[len(design)] = big.NewInt(1)
+	for i := len(design) - 1; i >= 0; i-- {
+		ways[i] = new(big.Int)
+		for length := range t.towels_at(design[i:]) {
+			ways[i].Add(ways[i], ways[i+length])
+		}
+	}
+	return ways[0]
 }
 
-// FindMatches takes in a string and a list of patterns and sees which of the building_blocks
-// can be used to build the string. It does this by calling inner_find_matches recursively.
-func FindMatches(to_create string, building_blocks []string, n_ways map[string]int) int {
-	// Filter available_patterns down to only those that are contained in to_create
-	bb := make([]string, 0, len(building_blocks))
-	for _, p := range building_blocks {
-		if strings.Contains(to_create, p) {
-			bb = append(bb, p)
+// CountWaysMod returns the number of ways to build `design` modulo `m`, which must be
+// positive. It never overflows, and is cheaper than CountWaysBig when only the count
+// modulo some number is needed.
+func (t *TowelTrie) CountWaysMod(design string, m int) int {
+	if m <= 0 {
+		panic("CountWaysMod: modulus must be positive")
+	}
+	ways := make([]int, len(design)+1)
+	ways[len(design)] = 1 % m
+	for i := len(design) - 1; i >= 0; i-- {
+		for length := range t.towels_at(design[i:]) {
+			// Both are below m, so subtracting rather than adding can't overflow
+			if ways[i] >= m-ways[i+length] {
+				ways[i] -= m - ways[i+length]
+			} else {
+				ways[i] += ways[i+length]
+			}
 		}
 	}
+	return ways[0]
+}
 
-	// If bb is empty, return 0
-	if len(bb) == 0 {
-		return 0
+// can_finish returns, for each position in `design`, whether what's left from there can
+// be built from the towels
+func (t *TowelTrie) can_finish(design string) []bool {
+	ok := make([]bool, len(design)+1)
+	ok[len(design)] = true
+	for i := len(design) - 1; i >= 0; i-- {
+		for length := range t.towels_at(design[i:]) {
+			if ok[i+length] {
+				ok[i] = true
+				break
+			}
+		}
 	}
-	inner_find_matches(to_create, bb, 0, n_ways)
-	return n_ways[to_create]
+	return ok
 }
 
-// prep_map prepares the map of how many ways we can build each pattern. Critically, it
-// also checks for any cases where a building block can be built in multiple ways. E.g.
-// if we have building blocks ["wr", "w", "r"], then we can build "wr" in two ways: "w" + "r" or "wr".
-// Likewise, if we have ["grb", "rb", "gr", "b","r","g"], then we can build "grb" as
-// "g" + "rb", "gr" + "b", "grb", or "g" + "r" + "b". The way to deal with this is to
-// start with the shortest building blocks and work our way up. This way, we can be sure that
-// we include any building blocks that can be built in multiple ways
-func prep_map(building_blocks []string) map[string]int {
-	// Sort the available_patterns by length, shortest first
-	slices.SortStableFunc(building_blocks, func(a, b string) int {
-		l_diff := len(a) - len(b)
-		if l_diff != 0 {
-			return l_diff
+// Arrangements yields every way to build `design` from the towels, as the list of towels
+// in order. Arrangements are found one at a time as they are needed, and never go down a
+// path that can't be finished, so stopping early is cheap even when there are trillions.
+// They come in order of the length of the first towel, then the second, and so on.
+//
+// The same slice is handed out each time, and changed in place. It is only good until
+// the next arrangement: use slices.Clone to keep one.
+func (t *TowelTrie) Arrangements(design string) iter.Seq[[]string] {
+	return func(yield func([]string) bool) {
+		can_finish := t.can_finish(design)
+		if !can_finish[0] {
+			return
 		}
-		return strings.Compare(a, b)
-	})
 
-	n_ways := make(map[string]int, len(building_blocks))
+		towels := make([]string, 0, len(design))
+		var walk func(i int) bool
+		walk = func(i int) bool {
+			if i == len(design) {
+				return yield(towels)
+			}
+			for length := range t.towels_at(design[i:]) {
+				if !can_finish[i+length] {
+					continue
+				}
+				towels = append(towels, design[i:i+length])
+				if !walk(i + length) {
+					return false
+				}
+				towels = towels[:len(towels)-1]
+			}
+			return true
+		}
+		walk(0)
+	}
+}
 
-	// Fill with the initial values
-	for _, bb := range building_blocks {
-		// If the bb is longer than 1 letter, then check how many ways we can build it
-		if len(bb) > 1 {
-			n_ways[bb] = FindMatches(bb, building_blocks, n_ways)
-		} else {
-			n_ways[bb] = 1
+// fewest_towels finds the fewest towels needed to build each prefix of `design`.
+// n_towels[i] is the fewest towels to build design[:i], or -1 if it can't be built, and
+// from[i] is where the last of those towels starts.
+func (t *TowelTrie) fewest_towels(design string) (n_towels, from []int) {
+	n_towels = make([]int, len(design)+1)
+	from = make([]int, len(design)+1)
+	for i := range n_towels {
+		n_towels[i] = -1
+	}
+	n_towels[0] = 0
+
+	for i := range len(design) {
+		if n_towels[i] == -1 {
+			continue
+		}
+		for length := range t.towels_at(design[i:]) {
+			j := i + length
+			if n_towels[j] == -1 || n_towels[i]+1 < n_towels[j] {
+				n_towels[j] = n_towels[i] + 1
+				from[j] = i
+			}
 		}
 	}
+	return n_towels, from
+}
 
-	return n_ways
+// towels_to follows `from` back from `end`, returning the towels that build design[:end]
+func towels_to(design string, from []int, end int) []string {
+	towels := make([]string, 0)
+	for end > 0 {
+		towels = append(towels, design[from[end]:end])
+		end = from[end]
+	}
+	slices.Reverse(towels)
+	return towels
 }
 
-func solve(desired_patterns []string, building_blocks []string) (int, int) {
+// FewestTowels returns an arrangement of `design` that uses as few towels as possible.
+// If there are ties, it picks the one found first when going from the start. It returns
+// false if the design can't be built.
+func (t *TowelTrie) FewestTowels(design string) ([]string, bool) {
+	n_towels, from := t.fewest_towels(design)
+	if n_towels[len(design)] == -1 {
+		return nil, false
+	}
+	return towels_to(design, from, len(design)), true
+}
+
+// LongestPrefix returns the longest start of `design` that can be built from the towels,
+// along with the fewest towels that build it. For designs that can be built this is the
+// whole design. Otherwise the design goes wrong at position len(prefix).
+func (t *TowelTrie) LongestPrefix(design string) (string, []string) {
+	n_towels, from := t.fewest_towels(design)
+	end := len(design)
+	for n_towels[end] == -1 {
+		end--
+	}
+	return design[:end], towels_to(design, from, end)
+}
 
-	// Create a map for keeping track of the number of ways we can build each pattern
-	// This is to avoid recalculating the number of ways to build a pattern
-	n_ways := prep_map(building_blocks)
+// solve counts the ways to build each design with a TowelTrie. Part 1 is how many of
+// the designs can be built, and part 2 is the total number of ways. Part 2 is a big.Int,
+// since the total can be too big for an int.
+func solve(desired_patterns []string, building_blocks []string) (int, *big.Int) {
+	t := NewTowelTrie(building_blocks)
 
 	p1_sum := 0
-	p2_sum := 0
+	p2_sum := new(big.Int)
 	for _, dp := range desired_patterns {
-		n := FindMatches(dp, building_blocks, n_ways)
-		if n > 0 {
+		n := t.CountWaysBig(dp)
+		if n.Sign() > 0 {
 			p1_sum += 1
-			p2_sum += n
+			p2_sum.Add(p2_sum, n)
 		}
 	}
-	// Try summing all the values in n_ways
-	p2_v2 := 0
-	for v := range maps.Values(n_ways) {
-		p2_v2 += v
-	}
 	return p1_sum, p2_sum
 }
 
+const usage = `usage: day19 [command design]
+
+With no arguments, solves parts 1 and 2. The commands look at a single design, using
+the towels from the puzzle input:
+
+  count design [m]    how many ways the design can be built, modulo m if given
+  list design [n]     the first n ways to build the design (default 10, 0 for all)
+  fewest design       a way to build the design with the fewest towels
+  prefix design       the longest start of the design that can be built`
+
+// run_command runs one of the commands for looking at a single design, writing the
+// result to `w`. args[0] is the command, and args[1] is the design.
+func run_command(w io.Writer, t *TowelTrie, args []string) error {
+	if len(args) < 2 {
+		return fmt.Errorf("expected a command and a design\n\n%s", usage)
+	}
+	cmd, design := args[0], args[1]
+
+	// The most arguments each command takes, including the command itself
+	max_args := map[string]int{"count": 3, "list": 3, "fewest": 2, "prefix": 2}
+	n, ok := max_args[cmd]
+	if !ok {
+		return fmt.Errorf("unknown command %q\n\n%s", cmd, usage)
+	}
+	if len(args) > n {
+		return fmt.Errorf("too many arguments for %q\n\n%s", cmd, usage)
+	}
+
+	switch cmd {
+	case "count":
+		if len(args) > 2 {
+			m, err := strconv.Atoi(args[2])
+			if err != nil || m <= 0 {
+				return fmt.Errorf("invalid modulus %q", args[2])
+			}
+			fmt.Fprintln(w, t.CountWaysMod(design, m))
+			break
+		}
+		fmt.Fprintln(w, t.CountWaysBig(design))
+
+	case "list":
+		limit := 10
+		if len(args) > 2 {
+			n, err := strconv.Atoi(args[2])
+			if err != nil || n < 0 {
+				return fmt.Errorf("invalid number of arrangements %q", args[2])
+			}
+			limit = n
+		}
+
+		shown := 0
+		for towels := range t.Arrangements(design) {
+			if limit > 0 && shown == limit {
+				more := new(big.Int).Sub(t.CountWaysBig(design), big.NewInt(int64(shown)))
+				fmt.Fprintf(w, "... and %v more\n", more)
+				break
+			}
+			fmt.Fprintln(w, strings.Join(towels, " "))
+			shown++
+		}
+		if shown == 0 {
+			fmt.Fprintln(w, "no arrangements")
+		}
+
+	case "fewest":
+		towels, ok := t.FewestTowels(design)
+		if !ok {
+			fmt.Fprintln(w, "no arrangements")
+			break
+		}
+		fmt.Fprintf(w, "%d towels: %s\n", len(towels), strings.Join(towels, " "))
+
+	case "prefix":
+		prefix, towels := t.LongestPrefix(design)
+		if len(prefix) == 0 && len(design) > 0 {
+			fmt.Fprintln(w, "no towels fit at the start")
+			break
+		}
+		fmt.Fprintf(w, "%d of %d letters: %s\n", len(prefix), len(design), strings.Join(towels, " "))
+		if len(prefix) < len(design) {
+			fmt.Fprintf(w, "stuck at: %s[%s]\n", prefix, design[len(prefix):])
+		}
+	}
+	return nil
+}
+
 // The input text of the puzzle
 //
 //go:embed input.txt
 var raw_text string
 
 func main() {
+	if len(os.Args) > 1 {
+		building_blocks, _ := parse_towels(raw_text)
+		if err := run_command(os.Stdout, NewTowelTrie(building_blocks), os.Args[1:]); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+		return
+	}
+
 	// === Parse Input ===============================================
 	parse_start := time.Now()
 	building_blocks, desired_patterns := parse_towels(raw_text)
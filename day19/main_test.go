@@ -1,8 +1,14 @@
 package main
 
 import (
+	"io"
+	"math"
+	"math/big"
+	"slices"
+	"strings"
 	"testing"
 
+	"github.com/natemcintosh/aoc_2024/utils/numth"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -17,27 +23,43 @@ bwurrg
 brgr
 bbrgwb`
 
-func TestPrepMap(t *testing.T) {
-	building_blocks := []string{"r", "wr", "b", "g", "bwu", "rb", "gb", "br"}
-	want := map[string]int{
-		"b":   1,
-		"r":   1,
-		"g":   1,
-		"wr":  1,
-		"rb":  2,
-		"gb":  2,
-		"br":  2,
-		"bwu": 1,
-	}
-	got := prep_map(building_blocks)
-	assert.Equal(t, want, got)
+func TestParseTowels(t *testing.T) {
+	building_blocks, desired_patterns := parse_towels(test_input)
+	want_building_blocks := []string{"r", "wr", "b", "g", "bwu", "rb", "gb", "br"}
+	want_desired_patterns := []string{"brwrr", "bggr", "gbbr", "rrbgbr", "ubwu", "bwurrg", "brgr", "bbrgwb"}
+
+	assert.Equal(t, want_building_blocks, building_blocks)
+	assert.Equal(t, want_desired_patterns, desired_patterns)
+
+	// Windows line endings parse the same
+	building_blocks, desired_patterns = parse_towels(strings.ReplaceAll(test_input, "\n", "\r\n") + "\r\n")
+	assert.Equal(t, want_building_blocks, building_blocks)
+	assert.Equal(t, want_desired_patterns, desired_patterns)
+}
+
+func TestPart1And2(t *testing.T) {
+	building_blocks, desired_patterns := parse_towels(test_input)
+	p1_want := 6
+	p2_want := int64(16)
+	p1_got, p2_got := solve(desired_patterns, building_blocks)
+	assert.Equal(t, p1_want, p1_got)
+	assert.Equal(t, p2_want, p2_got.Int64())
+}
+
+func TestPart1And2Real(t *testing.T) {
+	building_blocks, desired_patterns := parse_towels(raw_text)
+	p1_want := 276
+	p2_want := int64(681226908011510)
+	p1_got, p2_got := solve(desired_patterns, building_blocks)
+	assert.Equal(t, p1_want, p1_got)
+	assert.Equal(t, p2_want, p2_got.Int64())
 }
 
-func TestFindMatches(t *testing.T) {
-	building_blocks := []string{"r", "wr", "b", "g", "bwu", "rb", "gb", "br"}
+func TestCountWays(t *testing.T) {
+	trie := NewTowelTrie([]string{"r", "wr", "b", "g", "bwu", "rb", "gb", "br"})
 	tests := []struct {
-		pattern_to_build string
-		want_count       int
+		design string
+		want   int
 	}{
 		{"brwrr", 2},
 		{"bggr", 1},
@@ -47,35 +69,236 @@ func TestFindMatches(t *testing.T) {
 		{"bwurrg", 1},
 		{"brgr", 2},
 		{"bbrgwb", 0},
+		{"", 1},
+		{"rb", 2},
+		{"bwx", 0},
 	}
 	for _, tc := range tests {
-		got := FindMatches(tc.pattern_to_build, building_blocks, make(map[string]int))
-		assert.Equal(t, tc.want_count, got)
+		t.Run(tc.design, func(t *testing.T) {
+			got, err := trie.CountWays(tc.design)
+			assert.NoError(t, err)
+			assert.Equal(t, tc.want, got)
+			assert.Equal(t, int64(tc.want), trie.CountWaysBig(tc.design).Int64())
+			assert.Equal(t, tc.want%5, trie.CountWaysMod(tc.design, 5))
+		})
 	}
 }
 
-func TestParseTowels(t *testing.T) {
-	building_blocks, desired_patterns := parse_towels(test_input)
-	want_building_blocks := []string{"r", "wr", "b", "g", "bwu", "rb", "gb", "br"}
-	want_desired_patterns := []string{"brwrr", "bggr", "gbbr", "rrbgbr", "ubwu", "bwurrg", "brgr", "bbrgwb"}
+// count_ways_map counts the ways to build `rem` by trying every towel with
+// strings.HasPrefix. The number of ways to build a suffix doesn't depend on the design it
+// came from, so the memo can be shared between designs.
+func count_ways_map(rem string, towels []string, memo map[string]int) int {
+	if rem == "" {
+		return 1
+	}
+	if n, ok := memo[rem]; ok {
+		return n
+	}
+	n := 0
+	for _, towel := range towels {
+		if strings.HasPrefix(rem, towel) {
+			n += count_ways_map(rem[len(towel):], towels, memo)
+		}
+	}
+	memo[rem] = n
+	return n
+}
 
-	assert.Equal(t, want_building_blocks, building_blocks)
-	assert.Equal(t, want_desired_patterns, desired_patterns)
+// solve_map is the map based version of solve, kept as a baseline for the trie
+func solve_map(desired_patterns []string, building_blocks []string) (int, int) {
+	memo := make(map[string]int)
+	p1_sum, p2_sum := 0, 0
+	for _, dp := range desired_patterns {
+		n := count_ways_map(dp, building_blocks, memo)
+		if n > 0 {
+			p1_sum++
+		}
+		p2_sum += n
+	}
+	return p1_sum, p2_sum
 }
 
-func TestPart1And2(t *testing.T) {
-	building_blocks, desired_patterns := parse_towels(test_input)
-	p1_want := 6
-	p2_want := 16
-	p1_got, p2_got := solve(desired_patterns, building_blocks)
-	assert.Equal(t, p1_want, p1_got)
-	assert.Equal(t, p2_want, p2_got)
+func TestSolveMap(t *testing.T) {
+	for _, raw := range []string{test_input, raw_text} {
+		building_blocks, desired_patterns := parse_towels(raw)
+		p1_map, p2_map := solve_map(desired_patterns, building_blocks)
+		p1, p2 := solve(desired_patterns, building_blocks)
+		assert.Equal(t, p1, p1_map)
+		assert.Equal(t, p2.Int64(), int64(p2_map))
+	}
 }
 
-func TestPart1And2Real(t *testing.T) {
+func BenchmarkSolve(b *testing.B) {
 	building_blocks, desired_patterns := parse_towels(raw_text)
-	p1_want := 276
-	// p2_want
-	p1_got, _ := solve(desired_patterns, building_blocks)
-	assert.Equal(t, p1_want, p1_got)
+	for b.Loop() {
+		solve(desired_patterns, building_blocks)
+	}
+}
+
+func BenchmarkSolveMap(b *testing.B) {
+	building_blocks, desired_patterns := parse_towels(raw_text)
+	for b.Loop() {
+		solve_map(desired_patterns, building_blocks)
+	}
+}
+
+var example_towels = []string{"r", "wr", "b", "g", "bwu", "rb", "gb", "br"}
+
+func TestArrangements(t *testing.T) {
+	trie := NewTowelTrie(example_towels)
+
+	var got [][]string
+	for towels := range trie.Arrangements("gbbr") {
+		got = append(got, slices.Clone(towels))
+	}
+	want := [][]string{{"g", "b", "b", "r"}, {"g", "b", "br"}, {"gb", "b", "r"}, {"gb", "br"}}
+	assert.Equal(t, want, got)
+
+	// Every design gives as many arrangements as it has ways, and each builds the design
+	_, designs := parse_towels(test_input)
+	for _, design := range designs {
+		count := 0
+		for towels := range trie.Arrangements(design) {
+			assert.Equal(t, design, strings.Join(towels, ""))
+			count++
+		}
+		want, err := trie.CountWays(design)
+		assert.NoError(t, err)
+		assert.Equal(t, want, count, design)
+	}
+
+	// Stopping early works on designs with far too many arrangements to list
+	long := strings.Repeat("rb", 200)
+	count := 0
+	for range trie.Arrangements(long) {
+		count++
+		if count == 5 {
+			break
+		}
+	}
+	assert.Equal(t, 5, count)
+}
+
+func TestFewestTowels(t *testing.T) {
+	trie := NewTowelTrie(example_towels)
+	tests := []struct {
+		design string
+		want   []string
+		ok     bool
+	}{
+		{"brwrr", []string{"br", "wr", "r"}, true},
+		{"gbbr", []string{"gb", "br"}, true},
+		{"bwurrg", []string{"bwu", "r", "r", "g"}, true},
+		{"ubwu", nil, false},
+		{"", []string{}, true},
+	}
+	for _, tc := range tests {
+		t.Run(tc.design, func(t *testing.T) {
+			got, ok := trie.FewestTowels(tc.design)
+			assert.Equal(t, tc.ok, ok)
+			assert.Equal(t, tc.want, got)
+		})
+	}
+}
+
+func TestLongestPrefix(t *testing.T) {
+	trie := NewTowelTrie(example_towels)
+	tests := []struct {
+		design      string
+		want_prefix string
+		want_towels []string
+	}{
+		{"bbrgwb", "bbrg", []string{"b", "br", "g"}},
+		{"ubwu", "", []string{}},
+		{"brwrr", "brwrr", []string{"br", "wr", "r"}},
+		{"rrbx", "rrb", []string{"r", "rb"}},
+	}
+	for _, tc := range tests {
+		t.Run(tc.design, func(t *testing.T) {
+			prefix, towels := trie.LongestPrefix(tc.design)
+			assert.Equal(t, tc.want_prefix, prefix)
+			assert.Equal(t, tc.want_towels, towels)
+		})
+	}
+}
+
+func TestRunCommand(t *testing.T) {
+	trie := NewTowelTrie(example_towels)
+	tests := []struct {
+		args []string
+		want string
+	}{
+		{[]string{"count", "rrbgbr"}, "6\n"},
+		{[]string{"count", "rrbgbr", "4"}, "2\n"},
+		{[]string{"list", "brwrr"}, "b r wr r\nbr wr r\n"},
+		{[]string{"list", "rrbgbr", "2"}, "r r b g b r\nr r b g br\n... and 4 more\n"},
+		{[]string{"list", "ubwu"}, "no arrangements\n"},
+		{[]string{"fewest", "rrbgbr"}, "4 towels: r rb g br\n"},
+		{[]string{"fewest", "ubwu"}, "no arrangements\n"},
+		{[]string{"prefix", "bbrgwb"}, "4 of 6 letters: b br g\nstuck at: bbrg[wb]\n"},
+		{[]string{"prefix", "brwrr"}, "5 of 5 letters: br wr r\n"},
+		{[]string{"prefix", "ubwu"}, "no towels fit at the start\n"},
+	}
+	for _, tc := range tests {
+		t.Run(strings.Join(tc.args, " "), func(t *testing.T) {
+			var sb strings.Builder
+			assert.NoError(t, run_command(&sb, trie, tc.args))
+			assert.Equal(t, tc.want, sb.String())
+		})
+	}
+
+	assert.Error(t, run_command(io.Discard, trie, []string{"count"}))
+	assert.Error(t, run_command(io.Discard, trie, []string{"explode", "r"}))
+	assert.Error(t, run_command(io.Discard, trie, []string{"list", "r", "many"}))
+	assert.Error(t, run_command(io.Discard, trie, []string{"count", "r", "0"}))
+
+	// Extra arguments aren't ignored
+	for _, args := range [][]string{
+		{"count", "r", "5", "extra"},
+		{"list", "r", "5", "extra"},
+		{"fewest", "r", "extra"},
+		{"prefix", "r", "extra"},
+	} {
+		err := run_command(io.Discard, trie, args)
+		assert.ErrorContains(t, err, "too many arguments", args)
+		assert.ErrorContains(t, err, "usage:", args)
+	}
+}
+
+func TestCountWaysOverflow(t *testing.T) {
+	// With towels "r" and "rr", a design of n r's can be built in Fibonacci(n+1) ways.
+	// That's 7540113804746346429 for 91 r's, which fits in an int64, but the next one
+	// doesn't.
+	trie := NewTowelTrie([]string{"r", "rr"})
+
+	got, err := trie.CountWays(strings.Repeat("r", 91))
+	assert.NoError(t, err)
+	assert.Equal(t, 7540113804746346429, got)
+
+	_, err = trie.CountWays(strings.Repeat("r", 92))
+	assert.ErrorIs(t, err, numth.ErrOverflow)
+	assert.Equal(t, "12200160415121876738", trie.CountWaysBig(strings.Repeat("r", 92)).String())
+	assert.Equal(t, "573147844013817084101", trie.CountWaysBig(strings.Repeat("r", 100)).String())
+	assert.Equal(t, 782204094, trie.CountWaysMod(strings.Repeat("r", 100), 1_000_000_007))
+
+	// The biggest modulus still doesn't overflow
+	big_mod := new(big.Int).Mod(trie.CountWaysBig(strings.Repeat("r", 100)), big.NewInt(math.MaxInt))
+	assert.Equal(t, big_mod.Int64(), int64(trie.CountWaysMod(strings.Repeat("r", 100), math.MaxInt)))
+
+	// Longer towels make it grow even faster
+	trie = NewTowelTrie([]string{"r", "rr", "rrr"})
+	want := "52622583840983769603765180599790256716084480555530641"
+	assert.Equal(t, want, trie.CountWaysBig(strings.Repeat("r", 200)).String())
+	var sb strings.Builder
+	assert.NoError(t, run_command(&sb, trie, []string{"count", strings.Repeat("r", 200)}))
+	assert.Equal(t, want+"\n", sb.String())
+}
+
+func TestSolveOverflow(t *testing.T) {
+	// Part 2 adds up designs that each fit in an int, but not all together
+	towels := []string{"r", "rr"}
+	designs := []string{strings.Repeat("r", 91), strings.Repeat("r", 91), "g"}
+	p1, p2 := solve(designs, towels)
+	assert.Equal(t, 2, p1)
+	assert.Equal(t, "15080227609492692858", p2.String())
 }
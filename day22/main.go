@@ -1,8 +1,13 @@
 package main
 
 import (
+	"cmp"
 	_ "embed"
 	"fmt"
+	"io"
+	"iter"
+	"slices"
+	"strconv"
 	"strings"
 	"time"
 
@@ -10,16 +15,53 @@ import (
 )
 
 func parse(raw_text string) []int {
-	// Split on newlines, and parse each line as an int
-	lines := strings.Split(strings.TrimSpace(raw_text), "\n")
-
-	secret_numbers := make([]int, len(lines))
-	for i, line := range lines {
-		secret_numbers[i] = utils.ParseInt(line)
+	sr := NewSecretReader(strings.NewReader(raw_text))
+	secret_numbers := slices.Collect(sr.Secrets())
+	if err := sr.Err(); err != nil {
+		panic(err)
 	}
 	return secret_numbers
 }
 
+// SecretReader reads the secret number on each line of the input, without reading the
+// whole input in first.
+//
+// It is not suggested to create this struct directly. Instead, always use NewSecretReader
+type SecretReader struct {
+	lr  *utils.LineReader
+	err error
+}
+
+func NewSecretReader(r io.Reader) *SecretReader {
+	return &SecretReader{lr: utils.NewLineReader(r, 0)}
+}
+
+// Secrets yields the secret number on each line. Blank lines are skipped. If a line
+// can't be read or isn't a number, it stops, and Err says why.
+func (sr *SecretReader) Secrets() iter.Seq[int] {
+	return func(yield func(int) bool) {
+		for line_num, line := range sr.lr.Lines() {
+			if line == "" {
+				continue
+			}
+			secret, err := strconv.Atoi(strings.TrimSpace(line))
+			if err != nil {
+				sr.err = fmt.Errorf("line %d: %w", line_num, err)
+				return
+			}
+			if !yield(secret) {
+				return
+			}
+		}
+	}
+}
+
+// Err returns the first error found while reading, if any. Check it once the loop over
+// Secrets is done.
+func (sr *SecretReader) Err() error {
+	return cmp.Or(sr.err, sr.lr.Err())
+}
+
 // mix will calculate the bitwise XOR of the given value and the secret number. Then,
 // the secret number becomes the result of that operation. (If the secret number is 42
 // and you were to mix 15 into the secret number, the secret number would become 37.)
@@ -60,67 +102,228 @@ func step(secret int) int {
 	return secret
 }
 
-// ChangeTracker keeps track of the differences of 5 values. However, we only need the
-// i and (i - 1) values, and then we can keep track of the 4 sets of differences.
+// The secret numbers are 24 bits long, since prune keeps them below 2^24
+const (
+	secret_bits = 24
+	secret_mask = 1<<secret_bits - 1
+)
+
+// undo_xorshift_left undoes `s ^= s << k` on a 24 bit number. The lowest k bits of s
+// were not changed, and each block of k bits above that only depends on the block below
+// it, so XOR-ing in every multiple of the shift peels them off one by one.
+func undo_xorshift_left(s, k int) int {
+	res := s
+	for shift := k; shift < secret_bits; shift += k {
+		res ^= s << shift
+	}
+	return res & secret_mask
+}
+
+// undo_xorshift_right undoes `s ^= s >> k` on a 24 bit number, working down from the
+// top bits
+func undo_xorshift_right(s, k int) int {
+	res := s
+	for shift := k; shift < secret_bits; shift += k {
+		res ^= s >> shift
+	}
+	return res
+}
+
+// unstep is the inverse of step: unstep(step(s)) == s for every secret in [0, 2^24). It
+// undoes the three steps in reverse order.
+func unstep(secret int) int {
+	// Step 3 mixed in the secret times 2048, i.e. shifted left by 11
+	secret = undo_xorshift_left(secret, 11)
+
+	// Step 2 mixed in the secret divided by 32, i.e. shifted right by 5
+	secret = undo_xorshift_right(secret, 5)
+
+	// Step 1 mixed in the secret times 64, i.e. shifted left by 6
+	return undo_xorshift_left(secret, 6)
+}
+
+// StepMatrix is a 24×24 matrix over GF(2), i.e. of bits where adding is XOR. Each of
+// step's shifts, mixes and prunes is linear over GF(2), so the whole step is one of these
+// matrices, and n steps is that matrix to the power n.
+//
+// The matrix is stored by column: cols[i] is what bit i of a secret turns into.
+type StepMatrix [secret_bits]int
+
+// step_matrix returns the matrix that does one step
+func step_matrix() StepMatrix {
+	var m StepMatrix
+	for i := range m {
+		m[i] = step(1 << i)
+	}
+	return m
+}
+
+// identity_matrix returns the matrix that leaves secrets as they are
+func identity_matrix() StepMatrix {
+	var m StepMatrix
+	for i := range m {
+		m[i] = 1 << i
+	}
+	return m
+}
+
+// Apply multiplies the matrix by a secret, by XOR-ing the columns for each set bit
+func (m StepMatrix) Apply(secret int) int {
+	res := 0
+	for i := range m {
+		if secret&(1<<i) != 0 {
+			res ^= m[i]
+		}
+	}
+	return res
+}
+
+// Mul returns the matrix that applies `other` and then `m`
+func (m StepMatrix) Mul(other StepMatrix) StepMatrix {
+	var res StepMatrix
+	for i, col := range other {
+		res[i] = m.Apply(col)
+	}
+	return res
+}
+
+// Pow returns the matrix to the power `n`, by repeated squaring
+func (m StepMatrix) Pow(n int) StepMatrix {
+	res := identity_matrix()
+	for n > 0 {
+		if n&1 == 1 {
+			res = res.Mul(m)
+		}
+		m = m.Mul(m)
+		n >>= 1
+	}
+	return res
+}
+
+// jump gives the same result as step_n, but in O(log n) time, by raising the step matrix
+// to the power n. To jump many secrets by the same n, raise the matrix once and Apply it
+// to each. The first step prunes the secret to 24 bits, which the matrix does by only
+// looking at the low 24 bits, so taking no steps has to hand back the secret as it is.
+func jump(secret, n int) int {
+	if n == 0 {
+		return secret
+	}
+	return step_matrix().Pow(n).Apply(secret)
+}
+
+// Each change in the ones place is in [-9, 9], so there are 19 possible changes, and
+// 19^4 possible sets of 4 changes
+const (
+	n_changes = 19
+	n_keys    = n_changes * n_changes * n_changes * n_changes
+)
+
+// ChangeTracker keeps track of the last 4 differences between ones place values. The
+// differences are stored as a single number in base 19, with the oldest difference in
+// the highest digit, so they can be used to index into an array.
 //
 // It is not suggested to create this struct directly. Instead, always use NewChangeTracker
 type ChangeTracker struct {
-	// The values. New values move "in from the right", and old values move "out to the left"
-	vals [2]int
+	// The most recent value pushed
+	prev int
 
-	// Shift all the values to the left, and make the new right-most item
-	// diffs[3] = vals[1] - vals[0]
-	diffs [4]int
+	// The last 4 differences, each shifted up by 9 to make it a digit in [0, 18]
+	key int
 }
 
 func NewChangeTracker(vals [2]int) ChangeTracker {
-	diffs := [4]int{0, 0, 0, vals[1] - vals[0]}
-	return ChangeTracker{vals, diffs}
+	return ChangeTracker{vals[1], encode_changes([4]int{0, 0, 0, vals[1] - vals[0]})}
 }
 
 // Push will add a new value to the end of the list, and remove the first element. It
 // will also update the differences.
 func (ct *ChangeTracker) Push(value int) {
-	ct.vals[0] = ct.vals[1]
-	ct.vals[1] = value
+	// Shift every digit up one, dropping the oldest off the top
+	ct.key = (ct.key*n_changes + value - ct.prev + 9) % n_keys
+	ct.prev = value
+}
 
-	ct.diffs[0] = ct.diffs[1]
-	ct.diffs[1] = ct.diffs[2]
-	ct.diffs[2] = ct.diffs[3]
-	ct.diffs[3] = ct.vals[1] - ct.vals[0]
+// Key returns the last 4 differences as a number in [0, 19^4)
+func (ct ChangeTracker) Key() int {
+	return ct.key
 }
 
-// track_all_changes_for_seller will go through 2000 steps for a seller's starting
-// number, and keep track of the most recent 4 changes in ones place value of the secret
-// number. For set of 4 changes, it will store the value of the ones place. If a set
-// of 4 changes has already been seen, it will ignore this value.
-// If a set of 4 changes has not been seen, it will store the value of the ones place.
+// Diffs returns the last 4 differences, oldest first
+func (ct ChangeTracker) Diffs() [4]int {
+	return decode_changes(ct.key)
+}
+
+// encode_changes turns 4 differences into a key, as used by ChangeTracker
+func encode_changes(diffs [4]int) int {
+	key := 0
+	for _, d := range diffs {
+		key = key*n_changes + d + 9
+	}
+	return key
+}
+
+// decode_changes turns a key back into the 4 differences it was made from
+func decode_changes(key int) [4]int {
+	var diffs [4]int
+	for i := 3; i >= 0; i-- {
+		diffs[i] = key%n_changes - 9
+		key /= n_changes
+	}
+	return diffs
+}
+
+// BananaTracker sums up the bananas each set of 4 changes would get across sellers. It
+// is indexed by ChangeTracker.Key, rather than a map keyed by the changes.
 //
-// Finally, it will return a map of the values of the ones place for each set of 4 changes
-func track_all_changes_for_seller(secret, n_steps int, changes map[[4]int]int) map[[4]int]int {
-	// We re-use the allocations in changes to avoid allocations. So clear the values here
-	clear(changes)
+// It is not suggested to create this struct directly. Instead, always use
+// NewBananaTracker
+type BananaTracker struct {
+	totals []int
+
+	// To only count the first time each seller sees a set of changes, seen holds the
+	// number of the last seller to see each set. Then there's no need to clear anything
+	// between sellers.
+	seen   []int32
+	seller int32
+}
+
+func NewBananaTracker() *BananaTracker {
+	return &BananaTracker{totals: make([]int, n_keys), seen: make([]int32, n_keys)}
+}
+
+// track_all_changes_for_seller will go through `n_steps` steps for a seller's starting
+// number, and keep track of the most recent 4 changes in ones place value of the secret
+// number. The first time the seller sees each set of 4 changes, the value of the ones
+// place is added to the bananas for that set. Later times are ignored.
+func (bt *BananaTracker) track_all_changes_for_seller(secret, n_steps int) {
+	bt.seller++
 	ct := NewChangeTracker([2]int{ones_place(secret), ones_place(step(secret))})
 	secret = step_n(secret, 2)
 
 	for idx := 2; idx < n_steps; idx++ {
-		// Get the ones place of the secret number
 		ones := ones_place(secret)
-
-		// Add the ones place to the change tracker
 		ct.Push(ones)
 
 		// If the diffs have been filled with at least 4 values, then start checking
-		if idx >= 4 {
-			if _, ok := changes[ct.diffs]; !ok {
-				changes[ct.diffs] = ones
-			}
+		if idx >= 4 && bt.seen[ct.key] != bt.seller {
+			bt.seen[ct.key] = bt.seller
+			bt.totals[ct.key] += ones
 		}
 
-		// Step the secret number
 		secret = step(secret)
 	}
-	return changes
+}
+
+// Merge adds the bananas from `other` to this tracker
+func (bt *BananaTracker) Merge(other *BananaTracker) {
+	for key, n := range other.totals {
+		bt.totals[key] += n
+	}
+}
+
+// Most returns the most bananas any set of 4 changes would get
+func (bt *BananaTracker) Most() int {
+	return slices.Max(bt.totals)
 }
 
 // step_n repeats the step function n times
@@ -133,47 +336,50 @@ func step_n(secret int, n int) int {
 
 // Sum the values of the secret numbers after 2000 steps
 func part1(secrets []int) int {
+	return part1_seq(slices.Values(secrets))
+}
+
+// part1_seq is part1 for secret numbers that arrive one at a time. Every secret jumps
+// ahead the same number of steps, so the step matrix only needs to be raised once.
+func part1_seq(secrets iter.Seq[int]) int {
+	m := step_matrix().Pow(2000)
 	sum := 0
-	for _, secret := range secrets {
-		sum += step_n(secret, 2000)
+	for secret := range secrets {
+		sum += m.Apply(secret)
 	}
 	return sum
 }
 
-// merge_maps will merge two maps together, summing the values of any duplicate keys.
-// Note that this will modify the first map in place.
-func merge_maps(m1, m2 map[[4]int]int) map[[4]int]int {
-	for k, v := range m2 {
-		if existingValue, exists := m1[k]; exists {
-			m1[k] = existingValue + v
-		} else {
-			m1[k] = v
-		}
-	}
-	return m1
+func part2(secrets []int) int {
+	return part2_seq(slices.Values(secrets))
 }
 
-func part2(secrets []int) int {
-	// Track all the changes for each secret number
-	changes := make(map[[4]int]int, len(secrets))
-	changes2 := make(map[[4]int]int, len(secrets))
-
-	for _, secret := range secrets {
-		changes = merge_maps(
-			changes,
-			track_all_changes_for_seller(secret, 2000, changes2),
-		)
-	}
-
-	// Return the max value found
-	max_val := 0
-	for _, val := range changes {
-		if val > max_val {
-			max_val = val
-		}
+// part2_seq is part2 for secret numbers that arrive one at a time
+func part2_seq(secrets iter.Seq[int]) int {
+	// Track all the changes for each secret number, summing up the bananas each set of
+	// 4 changes would get across all of the sellers
+	bt := NewBananaTracker()
+	for secret := range secrets {
+		bt.track_all_changes_for_seller(secret, 2000)
 	}
+	return bt.Most()
+}
 
-	return max_val
+// part2_parallel is part2, with the sellers split up across `workers` goroutines. Each
+// worker totals up its own sellers, and then the totals are merged.
+func part2_parallel(secrets []int, workers int) int {
+	bt := utils.ParallelReduce(secrets, workers,
+		NewBananaTracker,
+		func(bt *BananaTracker, secret int) *BananaTracker {
+			bt.track_all_changes_for_seller(secret, 2000)
+			return bt
+		},
+		func(a, b *BananaTracker) *BananaTracker {
+			a.Merge(b)
+			return a
+		},
+	)
+	return bt.Most()
 }
 
 // The input text of the puzzle
@@ -195,7 +401,7 @@ func main() {
 
 	// === Part 2 ====================================================
 	p2_start := time.Now()
-	p2 := part2(secret_numbers)
+	p2 := part2_parallel(secret_numbers, 0)
 	p2_time := time.Since(p2_start)
 	fmt.Printf("Part 2: %v\n", p2)
 
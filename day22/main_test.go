@@ -1,9 +1,13 @@
 package main
 
 import (
+	"math/rand/v2"
+	"slices"
 	"strconv"
+	"strings"
 	"testing"
 
+	"github.com/natemcintosh/aoc_2024/utils"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -58,6 +62,56 @@ func TestStepN(t *testing.T) {
 	}
 }
 
+func TestUnstep(t *testing.T) {
+	// Check every possible secret. Only report the first failure, rather than millions.
+	for secret := range 1 << secret_bits {
+		stepped := step(secret)
+		if got := unstep(stepped); got != secret {
+			t.Fatalf("unstep(step(%d)) = %d", secret, got)
+		}
+		if stepped > secret_mask {
+			t.Fatalf("step(%d) = %d is more than 24 bits", secret, stepped)
+		}
+	}
+
+	// Walk back along the example sequence
+	assert.Equal(t, 123, unstep(15887950))
+	assert.Equal(t, 15887950, unstep(16495136))
+}
+
+func TestJump(t *testing.T) {
+	r := rand.New(rand.NewPCG(22, 22))
+	for _, n := range []int{0, 1, 2, 3, 10, 63, 64, 65, 1000, 2000} {
+		t.Run(strconv.Itoa(n), func(t *testing.T) {
+			for range 100 {
+				secret := r.IntN(1 << secret_bits)
+				assert.Equal(t, step_n(secret, n), jump(secret, n))
+			}
+		})
+	}
+
+	// Secrets wider than 24 bits get pruned by the first step, and left alone by no steps
+	for _, n := range []int{0, 1, 2000} {
+		secret := 1<<30 + 123
+		assert.Equal(t, step_n(secret, n), jump(secret, n))
+	}
+
+	// The test values from TestStepN
+	assert.Equal(t, 8685429, jump(1, 2000))
+	assert.Equal(t, 8667524, jump(2024, 2000))
+
+	// Matrix powers add up like step counts do
+	m := step_matrix()
+	assert.Equal(t, m.Pow(2000), m.Pow(1500).Mul(m.Pow(500)))
+	assert.Equal(t, identity_matrix(), m.Pow(0))
+}
+
+func BenchmarkJump(b *testing.B) {
+	for b.Loop() {
+		jump(2024, 2000)
+	}
+}
+
 func BenchmarkStepN(b *testing.B) {
 	for b.Loop() {
 		step_n(2024, 2000)
@@ -84,23 +138,107 @@ func TestPart1Real(t *testing.T) {
 	assert.Equal(t, want, got)
 }
 
-func TestTrackChanges(t *testing.T) {
-	secret := 123
+// track_all_changes_for_seller_map is the first version of tracking changes, keyed by
+// the changes in a map, and is kept as a reference for BananaTracker. It will go through
+// `n_steps` steps for a seller's starting number, and for each set of 4 changes, store
+// the value of the ones place the first time it is seen.
+//
+// Finally, it will return a map of the values of the ones place for each set of 4 changes
+func track_all_changes_for_seller_map(secret, n_steps int, changes map[[4]int]int) map[[4]int]int {
+	// We re-use the allocations in changes to avoid allocations. So clear the values here
+	clear(changes)
+	ct := NewChangeTracker([2]int{ones_place(secret), ones_place(step(secret))})
+	secret = step_n(secret, 2)
+
+	for idx := 2; idx < n_steps; idx++ {
+		// Get the ones place of the secret number
+		ones := ones_place(secret)
+
+		// Add the ones place to the change tracker
+		ct.Push(ones)
+
+		// If the diffs have been filled with at least 4 values, then start checking
+		if idx >= 4 {
+			diffs := ct.Diffs()
+			if _, ok := changes[diffs]; !ok {
+				changes[diffs] = ones
+			}
+		}
+
+		// Step the secret number
+		secret = step(secret)
+	}
+	return changes
+}
+
+// part2_map is part2 using the map based change tracking, as a reference to check and
+// benchmark against
+func part2_map(secrets []int) int {
+	changes := utils.NewCounter[[4]int](0)
 	changes2 := make(map[[4]int]int)
-	changes := track_all_changes_for_seller(secret, 10, changes2)
 
+	for _, secret := range secrets {
+		changes.Merge(track_all_changes_for_seller_map(secret, 2000, changes2))
+	}
+
+	// Return the max value found
+	best := changes.MostCommon(1)
+	if len(best) == 0 {
+		return 0
+	}
+	return best[0].Count
+}
+
+func TestTrackChanges(t *testing.T) {
 	want := map[[4]int]int{
 		{-3, 6, -1, -1}: 4, {6, -1, -1, 0}: 4,
 		{-1, -1, 0, 2}: 6, {-1, 0, 2, -2}: 4,
 		{0, 2, -2, 0}: 4, {2, -2, 0, -2}: 2,
 	}
+
+	bt := NewBananaTracker()
+	bt.track_all_changes_for_seller(123, 10)
+	got := make(map[[4]int]int)
+	for key, seller := range bt.seen {
+		if seller == bt.seller {
+			got[decode_changes(key)] = bt.totals[key]
+		}
+	}
+	assert.Equal(t, want, got)
+
+	changes := track_all_changes_for_seller_map(123, 10, make(map[[4]int]int))
 	assert.Equal(t, want, changes)
 }
 
+func TestTrackChangesOnlyCountsFirstSighting(t *testing.T) {
+	// Running the same seller twice gives twice the bananas, not more
+	once := NewBananaTracker()
+	once.track_all_changes_for_seller(2024, 2000)
+	twice := NewBananaTracker()
+	twice.track_all_changes_for_seller(2024, 2000)
+	twice.track_all_changes_for_seller(2024, 2000)
+	for key := range once.totals {
+		assert.Equal(t, 2*once.totals[key], twice.totals[key])
+	}
+}
+
 func TestNewChangeTracker(t *testing.T) {
-	want := ChangeTracker{[2]int{3, 0}, [4]int{0, 0, 0, -3}}
-	got := NewChangeTracker([2]int{3, 0})
-	assert.Equal(t, want, got)
+	ct := NewChangeTracker([2]int{3, 0})
+	assert.Equal(t, [4]int{0, 0, 0, -3}, ct.Diffs())
+
+	for _, v := range []int{9, 0, 9, 5} {
+		ct.Push(v)
+	}
+	assert.Equal(t, [4]int{9, -9, 9, -4}, ct.Diffs())
+	assert.Equal(t, encode_changes([4]int{9, -9, 9, -4}), ct.Key())
+}
+
+func TestEncodeChanges(t *testing.T) {
+	assert.Equal(t, 0, encode_changes([4]int{-9, -9, -9, -9}))
+	assert.Equal(t, n_keys-1, encode_changes([4]int{9, 9, 9, 9}))
+	for key := range n_keys {
+		assert.Equal(t, key, encode_changes(decode_changes(key)))
+	}
 }
 
 func TestPart2(t *testing.T) {
@@ -122,3 +260,56 @@ func BenchmarkPart2(b *testing.B) {
 		part2(nums)
 	}
 }
+
+func TestPart2Map(t *testing.T) {
+	assert.Equal(t, 23, part2_map([]int{1, 2, 3, 2024}))
+	assert.Equal(t, 1735, part2_map(parse(raw_text)))
+}
+
+func BenchmarkPart2Map(b *testing.B) {
+	nums := parse(raw_text)
+	for b.Loop() {
+		part2_map(nums)
+	}
+}
+
+func TestPart2Parallel(t *testing.T) {
+	nums := parse(raw_text)
+	for _, workers := range []int{0, 1, 3, 8} {
+		t.Run(strconv.Itoa(workers), func(t *testing.T) {
+			assert.Equal(t, 23, part2_parallel([]int{1, 2, 3, 2024}, workers))
+			assert.Equal(t, 1735, part2_parallel(nums, workers))
+		})
+	}
+}
+
+func BenchmarkPart2Parallel(b *testing.B) {
+	nums := parse(raw_text)
+	for _, workers := range []int{1, 2, 4, 8} {
+		b.Run(strconv.Itoa(workers), func(b *testing.B) {
+			for b.Loop() {
+				part2_parallel(nums, workers)
+			}
+		})
+	}
+}
+
+func TestPartsFromReader(t *testing.T) {
+	sr := NewSecretReader(strings.NewReader("1\r\n10\r\n100\r\n2024\r\n"))
+	assert.Equal(t, 37327623, part1_seq(sr.Secrets()))
+	assert.NoError(t, sr.Err())
+
+	sr = NewSecretReader(strings.NewReader(raw_text))
+	assert.Equal(t, 1735, part2_seq(sr.Secrets()))
+	assert.NoError(t, sr.Err())
+}
+
+func TestSecretReaderErrors(t *testing.T) {
+	// A bad line stops the reading, and says which line it was on
+	sr := NewSecretReader(strings.NewReader("1\n\n10\nten\n100\n"))
+	assert.Equal(t, []int{1, 10}, slices.Collect(sr.Secrets()))
+	assert.ErrorIs(t, sr.Err(), strconv.ErrSyntax)
+	assert.ErrorContains(t, sr.Err(), "line 4")
+
+	assert.Panics(t, func() { parse("1\n2\nthree") })
+}
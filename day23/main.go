@@ -3,10 +3,13 @@ package main
 import (
 	_ "embed"
 	"fmt"
+	"io"
 	"maps"
 	"slices"
 	"strings"
 	"time"
+
+	"github.com/natemcintosh/aoc_2024/utils"
 )
 
 // Node represents a node in a graph. It's made up of two runes
@@ -44,8 +47,8 @@ func NewNode(s string) (Node, error) {
 // Graph is an undirected graph. It stores both the nodes, and the edges between nodes.
 // Note that the Nodes map also stores the number of neighbors that node has.
 type Graph struct {
-	Edges map[[2]Node]struct{}
-	Nodes map[Node]int
+	Edges utils.Set[[2]Node]
+	Nodes utils.Counter[Node]
 }
 
 func (g Graph) Format(f fmt.State, c rune) {
@@ -64,7 +67,7 @@ func (g Graph) Format(f fmt.State, c rune) {
 // parse takes the input text and parses it into a graph
 func parse(raw_text string) Graph {
 	// Create the empty graph
-	g := Graph{make(map[[2]Node]struct{}), make(map[Node]int)}
+	g := Graph{utils.NewSet[[2]Node](), utils.NewCounter[Node](0)}
 	// For each line, for each pair of letters, add an edge to the graph
 	lines := strings.SplitSeq(strings.TrimSpace(raw_text), "\n")
 	for line := range lines {
@@ -87,11 +90,11 @@ func parse(raw_text string) Graph {
 		edge := CreateEdge(n1, n2)
 
 		// Add the edge to the graph
-		g.Edges[edge] = struct{}{}
+		g.Edges.Add(edge)
 
 		// Add the nodes to the graph
-		g.Nodes[n1] += 1
-		g.Nodes[n2] += 1
+		g.Nodes.Add(n1, 1)
+		g.Nodes.Add(n2, 1)
 
 	}
 	return g
@@ -108,9 +111,32 @@ func CreateEdge(n1, n2 Node) [2]Node {
 
 // HasEdge returns true if the graph has an edge between the two nodes
 func (g Graph) HasEdge(n1, n2 Node) bool {
-	edge := CreateEdge(n1, n2)
-	_, ok := g.Edges[edge]
-	return ok
+	return g.Edges.Contains(CreateEdge(n1, n2))
+}
+
+// Neighbors returns the set of nodes each node shares an edge with
+func (g Graph) Neighbors() map[Node]utils.Set[Node] {
+	neighbors := make(map[Node]utils.Set[Node], len(g.Nodes))
+	for n, degree := range g.Nodes {
+		neighbors[n] = make(utils.Set[Node], degree)
+	}
+	for e := range g.Edges {
+		neighbors[e[0]].Add(e[1])
+		neighbors[e[1]].Add(e[0])
+	}
+	return neighbors
+}
+
+// WriteDOT writes the graph in the Graphviz DOT language. The edges are undirected.
+func (g Graph) WriteDOT(w io.Writer, opts utils.ExportOptions[Node]) error {
+	opts.Directed = false
+	return utils.WriteDOT(w, slices.Collect(maps.Keys(g.Nodes)), slices.Collect(maps.Keys(g.Edges)), opts)
+}
+
+// WriteMermaid writes the graph as a Mermaid flowchart. The edges are undirected.
+func (g Graph) WriteMermaid(w io.Writer, opts utils.ExportOptions[Node]) error {
+	opts.Directed = false
+	return utils.WriteMermaid(w, slices.Collect(maps.Keys(g.Nodes)), slices.Collect(maps.Keys(g.Edges)), opts)
 }
 
 // compare_nodes compares two nodes. Compare by comparing the first rune, then the
@@ -131,58 +157,32 @@ func compare_nodes(a, b Node) int {
 	return 0
 }
 
-// compare_edges compares two edges. Compare by comparing the first node, then the
-// second. A node with a lower value is considered "less" than a node with a higher value.
-func compare_edges(a, b [2]Node) int {
-	if compare_nodes(a[0], b[0]) == 0 {
-		return compare_nodes(a[1], b[1])
-	}
-	return compare_nodes(a[0], b[0])
-}
-
+// part1 counts the triangles of computers with at least one whose name starts with `t`.
+// Every triangle is found from its first node, by looking at each pair of that node's
+// later neighbors and checking if they are connected.
 func part1(g Graph) int {
-	// Get a sorted slice of all the edges for easy, deterministic iteration
-	sorted_edges := slices.SortedFunc(maps.Keys(g.Edges), compare_edges)
-
-	triplets := make(map[[3]Node]struct{})
-
-	// For each edge, go forward in the list, and check for a common edge between the two nodes
-	for idx, e1 := range sorted_edges {
-		// If neither node starts with `t`, then continue
-		if e1[0][0] != 't' && e1[1][0] != 't' {
-			continue
+	neighbors := g.Neighbors()
+
+	count := 0
+	for n, ns := range neighbors {
+		// Only look at neighbors after `n`, so each triangle is counted from its first node
+		after := make([]Node, 0, len(ns))
+		for m := range ns {
+			if compare_nodes(n, m) < 0 {
+				after = append(after, m)
+			}
 		}
 
-		for _, e2 := range sorted_edges[idx:] {
-			// Check if either node in e1 matches either node in e2
-			var e3 [2]Node
-			if e1[0] == e2[0] {
-				e3 = CreateEdge(e1[1], e2[1])
-			} else if e1[0] == e2[1] {
-				e3 = CreateEdge(e1[1], e2[0])
-			} else if e1[1] == e2[0] {
-				e3 = CreateEdge(e1[0], e2[1])
-			} else if e1[1] == e2[1] {
-				e3 = CreateEdge(e1[0], e2[0])
-			} else {
+		for pair := range utils.Combinations(after, 2) {
+			if !neighbors[pair[0]].Contains(pair[1]) {
 				continue
 			}
-
-			// If so, check if the graph has a node made up of the other two nodes not
-			// yet checked
-			if !(g.HasEdge(e3[0], e3[1])) {
-				continue
+			if n[0] == 't' || pair[0][0] == 't' || pair[1][0] == 't' {
+				count++
 			}
-
-			// If so, sort the nodes, and put them in triplets
-			nodes := []Node{e1[0], e1[1], e2[0], e2[1], e3[0], e3[1]}
-			slices.SortStableFunc(nodes, compare_nodes)
-			nodes = slices.Compact(nodes)
-			triplets[[3]Node{nodes[0], nodes[1], nodes[2]}] = struct{}{}
 		}
 	}
-
-	return len(triplets)
+	return count
 }
 
 // FullyConnected is a set of nodes that are all connected to each other.
@@ -201,37 +201,74 @@ func (fc *FullyConnected) AddIfPossible(n Node, g Graph) bool {
 	return true
 }
 
-func part2(g Graph) string {
-	// Create a pool of fully connected sub-graphs
-	fcg_pool := make([]FullyConnected, 0, len(g.Nodes))
-
-	// Iterate over the nodes
-	for n := range g.Nodes {
-		// Attempt to add it to all existing FCGs
-		for idx, fcg := range fcg_pool {
-			can_add := fcg.AddIfPossible(n, g)
-			if can_add {
-				fcg_pool[idx].Nodes = append(fcg_pool[idx].Nodes, n)
+// Highlight returns export options that highlight every node in the fully connected
+// sub-graph, along with all of the edges between them.
+func (fc FullyConnected) Highlight() utils.ExportOptions[Node] {
+	opts := utils.ExportOptions[Node]{
+		HighlightNodes: make(map[Node]struct{}, len(fc.Nodes)),
+		HighlightEdges: make(map[[2]Node]struct{}),
+	}
+	for idx, n1 := range fc.Nodes {
+		opts.HighlightNodes[n1] = struct{}{}
+		for _, n2 := range fc.Nodes[idx+1:] {
+			opts.HighlightEdges[CreateEdge(n1, n2)] = struct{}{}
+		}
+	}
+	return opts
+}
+
+// MaxClique finds the largest fully connected sub-graph using the [Bron–Kerbosch
+// algorithm](wikipedia.org/Bron–Kerbosch_algorithm) with pivoting. If there are several
+// of the same size, the one that comes first alphabetically is returned. The nodes are
+// returned sorted.
+func (g Graph) MaxClique() FullyConnected {
+	neighbors := g.Neighbors()
+	var best []Node
+
+	// r is the clique being built up, p holds the nodes that could still be added to
+	// it, and x holds the nodes that have already been tried, so they aren't reported
+	// twice.
+	var bron_kerbosch func(r []Node, p, x utils.Set[Node])
+	bron_kerbosch = func(r []Node, p, x utils.Set[Node]) {
+		if len(p) == 0 && len(x) == 0 {
+			// r can't be grown any more. Keep it if it beats the best so far
+			clique := slices.Clone(r)
+			slices.SortFunc(clique, compare_nodes)
+			if len(clique) > len(best) ||
+				(len(clique) == len(best) && slices.CompareFunc(clique, best, compare_nodes) < 0) {
+				best = clique
 			}
+			return
 		}
 
-		// Add a new FCG that has just this one.
-		fcg_pool = append(fcg_pool, FullyConnected{[]Node{n}})
-	}
+		// Any maximal clique must include either the pivot or one of its non-neighbors,
+		// so only those need to be tried. Pick the pivot with the most neighbors in p.
+		var pivot Node
+		n_pivot := -1
+		for u := range p.Union(x).Sorted(compare_nodes) {
+			if n := len(p.Intersection(neighbors[u])); n > n_pivot {
+				pivot, n_pivot = u, n
+			}
+		}
 
-	// Sort the fully connected graphs by how many nodes they have, most first
-	slices.SortStableFunc(fcg_pool, func(a, b FullyConnected) int {
-		if len(a.Nodes) > len(b.Nodes) {
-			return -1
-		} else if len(a.Nodes) < len(b.Nodes) {
-			return 1
+		for v := range p.Difference(neighbors[pivot]).Sorted(compare_nodes) {
+			bron_kerbosch(
+				append(r, v),
+				p.Intersection(neighbors[v]),
+				x.Intersection(neighbors[v]),
+			)
+			p.Remove(v)
+			x.Add(v)
 		}
-		return 0
-	})
+	}
+
+	bron_kerbosch(nil, utils.CollectSet(maps.Keys(g.Nodes)), utils.NewSet[Node]())
+	return FullyConnected{best}
+}
 
-	// Get the nodes in the largest FCG
-	biggest_fcg := fcg_pool[0].Nodes
-	slices.SortStableFunc(biggest_fcg, compare_nodes)
+func part2(g Graph) string {
+	// Get the nodes in the largest fully connected sub-graph
+	biggest_fcg := g.MaxClique().Nodes
 
 	// Get all the nodes, and join them
 	var res strings.Builder
@@ -1,8 +1,11 @@
 package main
 
 import (
+	"slices"
+	"strings"
 	"testing"
 
+	"github.com/natemcintosh/aoc_2024/utils"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -131,6 +134,67 @@ func TestPart1Real(t *testing.T) {
 	assert.Equal(t, want, got)
 }
 
+// compare_edges compares two edges. Compare by comparing the first node, then the
+// second. A node with a lower value is considered "less" than a node with a higher value.
+func compare_edges(a, b [2]Node) int {
+	if compare_nodes(a[0], b[0]) == 0 {
+		return compare_nodes(a[1], b[1])
+	}
+	return compare_nodes(a[0], b[0])
+}
+
+// part1_edges is the first version of part1, which looks at each pair of edges. It is
+// kept as a reference for part1.
+func part1_edges(g Graph) int {
+	// Get a sorted slice of all the edges for easy, deterministic iteration
+	sorted_edges := slices.Collect(g.Edges.Sorted(compare_edges))
+
+	triplets := utils.NewSet[[3]Node]()
+
+	// For each edge, go forward in the list, and check for a common edge between the two nodes
+	for idx, e1 := range sorted_edges {
+		// If neither node starts with `t`, then continue
+		if e1[0][0] != 't' && e1[1][0] != 't' {
+			continue
+		}
+
+		for _, e2 := range sorted_edges[idx:] {
+			// Check if either node in e1 matches either node in e2
+			var e3 [2]Node
+			if e1[0] == e2[0] {
+				e3 = CreateEdge(e1[1], e2[1])
+			} else if e1[0] == e2[1] {
+				e3 = CreateEdge(e1[1], e2[0])
+			} else if e1[1] == e2[0] {
+				e3 = CreateEdge(e1[0], e2[1])
+			} else if e1[1] == e2[1] {
+				e3 = CreateEdge(e1[0], e2[0])
+			} else {
+				continue
+			}
+
+			// If so, check if the graph has a node made up of the other two nodes not
+			// yet checked
+			if !(g.HasEdge(e3[0], e3[1])) {
+				continue
+			}
+
+			// If so, sort the nodes, and put them in triplets
+			nodes := []Node{e1[0], e1[1], e2[0], e2[1], e3[0], e3[1]}
+			slices.SortStableFunc(nodes, compare_nodes)
+			nodes = slices.Compact(nodes)
+			triplets.Add([3]Node{nodes[0], nodes[1], nodes[2]})
+		}
+	}
+
+	return len(triplets)
+}
+
+func TestPart1Edges(t *testing.T) {
+	assert.Equal(t, 7, part1_edges(parse(test_input)))
+	assert.Equal(t, part1_edges(parse(raw_text)), part1(parse(raw_text)))
+}
+
 func BenchmarkPart1(b *testing.B) {
 	benchmarks := []struct {
 		name  string
@@ -180,3 +244,93 @@ func TestPart2(t *testing.T) {
 	got := part2(g)
 	assert.Equal(t, want, got)
 }
+
+func TestWriteDOT(t *testing.T) {
+	g := parse(`ka-co
+ta-co
+ta-ka
+de-co`)
+	opts := FullyConnected{[]Node{{'k', 'a'}, {'c', 'o'}, {'t', 'a'}}}.Highlight()
+	opts.Cluster = utils.ClusterByPrefix[Node]("t")
+
+	var sb strings.Builder
+	err := g.WriteDOT(&sb, opts)
+	assert.NoError(t, err)
+
+	want := `graph "G" {
+  n0 [color="red", label="co", penwidth="2"];
+  n1 [label="de"];
+  n2 [color="red", label="ka", penwidth="2"];
+  subgraph "cluster_t" {
+    label="t";
+    n3 [color="red", label="ta", penwidth="2"];
+  }
+  n0 -- n1;
+  n0 -- n2 [color="red", penwidth="2"];
+  n0 -- n3 [color="red", penwidth="2"];
+  n2 -- n3 [color="red", penwidth="2"];
+}
+`
+	assert.Equal(t, want, sb.String())
+}
+
+func TestWriteMermaid(t *testing.T) {
+	g := parse(`ka-co
+de-co`)
+	opts := FullyConnected{[]Node{{'k', 'a'}, {'c', 'o'}}}.Highlight()
+
+	var sb strings.Builder
+	err := g.WriteMermaid(&sb, opts)
+	assert.NoError(t, err)
+
+	want := `flowchart LR
+  n0["co"]
+  n1["de"]
+  n2["ka"]
+  n0 --- n1
+  n0 --- n2
+  classDef highlight stroke:red,stroke-width:2px
+  class n0,n2 highlight
+  linkStyle 1 stroke:red,stroke-width:2px
+`
+	assert.Equal(t, want, sb.String())
+}
+
+func TestNeighbors(t *testing.T) {
+	g := parse(`ka-co
+ta-co
+de-co`)
+	want := map[Node]utils.Set[Node]{
+		{'c', 'o'}: utils.NewSet(Node{'k', 'a'}, Node{'t', 'a'}, Node{'d', 'e'}),
+		{'k', 'a'}: utils.NewSet(Node{'c', 'o'}),
+		{'t', 'a'}: utils.NewSet(Node{'c', 'o'}),
+		{'d', 'e'}: utils.NewSet(Node{'c', 'o'}),
+	}
+	assert.Equal(t, want, g.Neighbors())
+}
+
+func TestMaxClique(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		want  []Node
+	}{
+		{"example", test_input, []Node{{'c', 'o'}, {'d', 'e'}, {'k', 'a'}, {'t', 'a'}}},
+		// Two triangles of the same size, so the first alphabetically is picked
+		{"tie", "xa-xb\nxb-xc\nxa-xc\naa-ab\nab-ac\naa-ac", []Node{{'a', 'a'}, {'a', 'b'}, {'a', 'c'}}},
+		{"single edge", "ab-cd", []Node{{'a', 'b'}, {'c', 'd'}}},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			got := parse(tc.input).MaxClique()
+			assert.Equal(t, tc.want, got.Nodes)
+		})
+	}
+}
+
+func BenchmarkPart2(b *testing.B) {
+	g := parse(raw_text)
+	for b.Loop() {
+		part2(g)
+	}
+}
@@ -4,40 +4,35 @@ import (
 	_ "embed"
 	"fmt"
 	"slices"
-	"strconv"
-	"strings"
 	"time"
 
 	"github.com/natemcintosh/aoc_2024/circuits"
+	"github.com/natemcintosh/aoc_2024/utils"
 )
 
 func part1() int {
 	// Get the input values
 	x, y := circuits.InputValues()
 
-	// Run the circut, and gather the z outputs
-	z := circuits.Circuit(x, y)
-
-	// Create a string of ones and zeros, where the earlier index is farther to the
-	// right
-	slices.Reverse(z)
-	var str_z strings.Builder
-	for _, bit := range z {
-		if bit {
-			str_z.WriteString("1")
-		} else {
-			str_z.WriteString("0")
-		}
+	// Run the circut, and gather the z outputs. Each bool is a bit in a binary number,
+	// with the earliest values at the lowest bit.
+	z, ok := utils.BitsetFromBools(circuits.Circuit(x, y)).Uint64()
+	if !ok {
+		panic("z does not fit in 64 bits")
 	}
-	z_bits := str_z.String()
+	return int(z)
+}
 
-	// Intepret each bool as a bit in a binary number. Earliest values
-	// are at the lowest bit.
-	parsed_val, err := strconv.ParseInt(z_bits, 2, 64)
-	if err != nil {
-		panic(err)
-	}
-	return int(parsed_val)
+// wrong_z_bits returns the output bits where the circuit does not match adding x and y
+// together, which is where to start looking for swapped wires
+func wrong_z_bits() []int {
+	x, y := circuits.InputValues()
+	z := utils.BitsetFromBools(circuits.Circuit(x, y))
+
+	// The sum is one bit wider than the inputs
+	extra := []bool{false}
+	bx, by := utils.BitsetFromBools(slices.Concat(x, extra)), utils.BitsetFromBools(slices.Concat(y, extra))
+	return slices.Collect(z.Xor(bx.Add(by)).All())
 }
 
 func main() {
@@ -53,6 +48,9 @@ func main() {
 	p2_time := time.Since(p2_start)
 	// fmt.Printf("Part 2: %v\n", p2)
 
+	// Where the circuit goes wrong, to help find the swapped wires by hand
+	fmt.Printf("Wrong z bits: %v\n", wrong_z_bits())
+
 	// === Print Results ============================================
 	fmt.Printf("\nPart 1 took %v\n", p1_time)
 	fmt.Printf("Part 2 took %v\n", p2_time)
@@ -11,3 +11,9 @@ func TestPart1Real(t *testing.T) {
 	want := 36035961805936
 	assert.Equal(t, want, got)
 }
+
+func TestWrongZBits(t *testing.T) {
+	want := []int{11, 12, 13, 15, 16, 17, 18, 19, 20, 37, 38}
+	got := wrong_z_bits()
+	assert.Equal(t, want, got)
+}
@@ -5,6 +5,8 @@ import (
 	"fmt"
 	"strings"
 	"time"
+
+	"github.com/natemcintosh/aoc_2024/utils"
 )
 
 // LockKey represents the five numbers that make up a lock or a key. They are always
@@ -27,6 +29,22 @@ func (lk LockKey) fits(other LockKey) bool {
 	return false
 }
 
+// mask returns a Bitset with a bit set for each '#' in the five middle rows, where bit
+// 5*column + row is set. Locks hang down from the top, and keys stick up from the bottom.
+func (lk LockKey) mask(is_lock bool) *utils.Bitset {
+	b := utils.NewBitset(25)
+	for col, height := range lk.vals {
+		for r := range int(height) {
+			row := r
+			if !is_lock {
+				row = 4 - r
+			}
+			b.Set(5*col + row)
+		}
+	}
+	return b
+}
+
 // parse_lockey parses a string representing a lock or a key into a LockKey struct. If
 // the first line is all dots (`.....`) then it is a key, otherwise it is a lock. The
 // first and last lines do not contribute to the count. If it is a key, the returned
@@ -60,10 +78,8 @@ func parse_lockey(raw_text string) (lk LockKey, is_lock bool) {
 // parse reads the raw text, and converts it into two slices of locks and keys.
 func parse(raw_text string) (locks, keys []LockKey) {
 	// Split on double new lines
-	lock_keys := strings.SplitSeq(raw_text, "\n\n")
-
-	for lock_key := range lock_keys {
-		lk, is_lock := parse_lockey(lock_key)
+	for _, lock_key := range utils.Sections(raw_text) {
+		lk, is_lock := parse_lockey(lock_key.Text)
 		if is_lock {
 			locks = append(locks, lk)
 		} else {
@@ -74,11 +90,19 @@ func parse(raw_text string) (locks, keys []LockKey) {
 	return
 }
 
+// part1 counts the lock and key pairs that fit together. Each lock and key is turned
+// into a mask of its '#'s, so a pair fits if the masks have no bits in common.
 func part1(locks, keys []LockKey) int {
+	key_masks := make([]*utils.Bitset, len(keys))
+	for i, k := range keys {
+		key_masks[i] = k.mask(false)
+	}
+
 	sum := 0
 	for _, l := range locks {
-		for _, k := range keys {
-			if l.fits(k) {
+		lock_mask := l.mask(true)
+		for _, k := range key_masks {
+			if !lock_mask.Intersects(k) {
 				sum += 1
 			}
 		}
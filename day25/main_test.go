@@ -61,3 +61,36 @@ func TestParseLockKey(t *testing.T) {
 	}
 
 }
+
+func TestMask(t *testing.T) {
+	lock := LockKey{[5]uint8{0, 5, 3, 4, 3}}
+	key := LockKey{[5]uint8{5, 0, 2, 1, 3}}
+
+	// Columns are 5 bits each, with the lowest column on the right
+	assert.Equal(t, "0011101111001111111100000", lock.mask(true).String())
+	assert.Equal(t, "1110010000110000000011111", key.mask(false).String())
+
+	// They overlap in the last column
+	assert.False(t, lock.fits(key))
+	assert.True(t, lock.mask(true).Intersects(key.mask(false)))
+}
+
+// part1_fits is the first version of part1, which checks each pair column by column. It
+// is kept as a reference for the masks.
+func part1_fits(locks, keys []LockKey) int {
+	sum := 0
+	for _, l := range locks {
+		for _, k := range keys {
+			if l.fits(k) {
+				sum += 1
+			}
+		}
+	}
+	return sum
+}
+
+func TestPart1Masks(t *testing.T) {
+	locks, keys := parse(raw_text)
+	assert.Equal(t, 2618, part1(locks, keys))
+	assert.Equal(t, part1_fits(locks, keys), part1(locks, keys))
+}
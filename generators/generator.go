@@ -12,13 +12,12 @@ import (
 
 func GenerateCircuit(raw_text string) {
 	// Break up the raw text by double newlines
-	text := strings.SplitN(strings.TrimSpace(raw_text), "\n\n", 2)
-	if len(text) < 2 {
-		log.Println(text)
-		log.Fatalf("Invalid input format")
+	text, err := utils.SectionsN(raw_text, 2)
+	if err != nil {
+		log.Fatalf("Invalid input format: %v", err)
 	}
-	str_vars := strings.Split(text[0], "\n")
-	str_gates := strings.Split(text[1], "\n")
+	str_vars := text[0].Lines()
+	str_gates := text[1].Lines()
 
 	// Create the function that returns the x and y values in the input file
 	// input_vals := CreateInputVals
@@ -0,0 +1,427 @@
+package utils
+
+import (
+	"fmt"
+	"iter"
+	"math/big"
+	"math/bits"
+	"strings"
+)
+
+// Bitset is a set of small non-negative integers, stored one bit each. Bit 0 is the
+// lowest bit, so a Bitset can also be read as a binary number.
+//
+// A fixed Bitset has a width set when it's made, and any bits pushed past the width
+// (e.g. by Lsh or Add) are dropped. A growable Bitset gets wider as needed instead.
+type Bitset struct {
+	words    []uint64
+	width    int
+	growable bool
+}
+
+// n_words is how many uint64s are needed to hold `width` bits
+func n_words(width int) int {
+	return (width + 63) / 64
+}
+
+// NewBitset makes a fixed Bitset that holds bits 0 to width-1, all unset
+func NewBitset(width int) *Bitset {
+	if width < 0 {
+		panic("NewBitset: negative width")
+	}
+	return &Bitset{make([]uint64, n_words(width)), width, false}
+}
+
+// NewGrowableBitset makes an empty Bitset that gets wider whenever a bit past the end is
+// set
+func NewGrowableBitset() *Bitset {
+	return &Bitset{nil, 0, true}
+}
+
+// BitsetFromUint64 makes a fixed Bitset of `width` bits holding the lowest bits of `v`
+func BitsetFromUint64(v uint64, width int) *Bitset {
+	b := NewBitset(width)
+	if len(b.words) > 0 {
+		b.words[0] = v
+		b.trim()
+	}
+	return b
+}
+
+// BitsetFromBools makes a fixed Bitset with bit i set if bools[i] is true
+func BitsetFromBools(bools []bool) *Bitset {
+	b := NewBitset(len(bools))
+	for idx, v := range bools {
+		if v {
+			b.words[idx/64] |= 1 << (idx % 64)
+		}
+	}
+	return b
+}
+
+// BitsetFromBig makes a Bitset from a non-negative big.Int. If width is 0, it makes a
+// growable Bitset just wide enough to hold it. Otherwise it is fixed, keeping the lowest
+// `width` bits.
+func BitsetFromBig(n *big.Int, width int) *Bitset {
+	if n.Sign() < 0 {
+		panic("BitsetFromBig: negative number")
+	}
+	var b *Bitset
+	if width == 0 {
+		b = NewGrowableBitset()
+		b.resize(n.BitLen())
+	} else {
+		b = NewBitset(width)
+	}
+	for idx := range min(b.width, n.BitLen()) {
+		if n.Bit(idx) == 1 {
+			b.words[idx/64] |= 1 << (idx % 64)
+		}
+	}
+	return b
+}
+
+// ParseBitset reads a binary string like "10110", with the highest bit first, into a
+// fixed Bitset as wide as the string
+func ParseBitset(s string) (*Bitset, error) {
+	b := NewBitset(len(s))
+	for idx := range len(s) {
+		bit := len(s) - 1 - idx
+		switch s[idx] {
+		case '1':
+			b.words[bit/64] |= 1 << (bit % 64)
+		case '0':
+		default:
+			return nil, fmt.Errorf("ParseBitset: invalid character %q at %d", s[idx], idx)
+		}
+	}
+	return b, nil
+}
+
+// Len is the width of the Bitset in bits
+func (b *Bitset) Len() int {
+	return b.width
+}
+
+// Growable says if the Bitset gets wider when needed
+func (b *Bitset) Growable() bool {
+	return b.growable
+}
+
+// Clone returns a copy that doesn't share memory with `b`
+func (b *Bitset) Clone() *Bitset {
+	return &Bitset{append([]uint64(nil), b.words...), b.width, b.growable}
+}
+
+// resize changes the width, dropping any bits past the new width
+func (b *Bitset) resize(width int) {
+	n := n_words(width)
+	if n > len(b.words) {
+		b.words = append(b.words, make([]uint64, n-len(b.words))...)
+	}
+	b.words = b.words[:n]
+	b.width = width
+	b.trim()
+}
+
+// trim clears any bits in the last word past the width, so that comparing and counting
+// whole words works
+func (b *Bitset) trim() {
+	if extra := b.width % 64; extra != 0 {
+		b.words[len(b.words)-1] &= (1 << extra) - 1
+	}
+}
+
+// check makes sure bit `i` can be used, growing the Bitset if it can
+func (b *Bitset) check(i int) {
+	if i < 0 {
+		panic(fmt.Sprintf("Bitset: negative bit %d", i))
+	}
+	if i >= b.width {
+		if !b.growable {
+			panic(fmt.Sprintf("Bitset: bit %d out of range for width %d", i, b.width))
+		}
+		b.resize(i + 1)
+	}
+}
+
+// Test says if bit `i` is set. Bits past the width are never set.
+func (b *Bitset) Test(i int) bool {
+	if i < 0 || i >= b.width {
+		return false
+	}
+	return b.words[i/64]&(1<<(i%64)) != 0
+}
+
+// Set turns on bit `i`. For a fixed Bitset it panics if `i` is past the width.
+func (b *Bitset) Set(i int) {
+	b.check(i)
+	b.words[i/64] |= 1 << (i % 64)
+}
+
+// Clear turns off bit `i`
+func (b *Bitset) Clear(i int) {
+	if i >= 0 && i < b.width {
+		b.words[i/64] &^= 1 << (i % 64)
+	}
+}
+
+// Flip toggles bit `i`. For a fixed Bitset it panics if `i` is past the width.
+func (b *Bitset) Flip(i int) {
+	b.check(i)
+	b.words[i/64] ^= 1 << (i % 64)
+}
+
+// SetTo turns bit `i` on or off
+func (b *Bitset) SetTo(i int, v bool) {
+	if v {
+		b.Set(i)
+	} else {
+		b.Clear(i)
+	}
+}
+
+// Count returns how many bits are set
+func (b *Bitset) Count() int {
+	count := 0
+	for _, w := range b.words {
+		count += bits.OnesCount64(w)
+	}
+	return count
+}
+
+// Any says if any bit is set
+func (b *Bitset) Any() bool {
+	for _, w := range b.words {
+		if w != 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// Equal says if both Bitsets have the same bits set, whatever their widths
+func (b *Bitset) Equal(other *Bitset) bool {
+	for idx := range max(len(b.words), len(other.words)) {
+		if b.word(idx) != other.word(idx) {
+			return false
+		}
+	}
+	return true
+}
+
+// Intersects says if any bit is set in both, without making a new Bitset
+func (b *Bitset) Intersects(other *Bitset) bool {
+	for idx := range min(len(b.words), len(other.words)) {
+		if b.words[idx]&other.words[idx] != 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// word returns word `idx`, or 0 if it is past the end
+func (b *Bitset) word(idx int) uint64 {
+	if idx < len(b.words) {
+		return b.words[idx]
+	}
+	return 0
+}
+
+// empty_like makes an empty Bitset for the result of an operation on `b` and `other`.
+// It is as wide as the wider input, and growable if either input is.
+func (b *Bitset) empty_like(other *Bitset) *Bitset {
+	width := max(b.width, other.width)
+	return &Bitset{make([]uint64, n_words(width)), width, b.growable || other.growable}
+}
+
+// combine applies `op` to each pair of words
+func (b *Bitset) combine(other *Bitset, op func(x, y uint64) uint64) *Bitset {
+	res := b.empty_like(other)
+	for idx := range res.words {
+		res.words[idx] = op(b.word(idx), other.word(idx))
+	}
+	res.trim()
+	return res
+}
+
+// And returns the bits set in both
+func (b *Bitset) And(other *Bitset) *Bitset {
+	return b.combine(other, func(x, y uint64) uint64 { return x & y })
+}
+
+// Or returns the bits set in either
+func (b *Bitset) Or(other *Bitset) *Bitset {
+	return b.combine(other, func(x, y uint64) uint64 { return x | y })
+}
+
+// Xor returns the bits set in exactly one
+func (b *Bitset) Xor(other *Bitset) *Bitset {
+	return b.combine(other, func(x, y uint64) uint64 { return x ^ y })
+}
+
+// AndNot returns the bits set in `b` but not in `other`
+func (b *Bitset) AndNot(other *Bitset) *Bitset {
+	return b.combine(other, func(x, y uint64) uint64 { return x &^ y })
+}
+
+// Not flips every bit up to the width
+func (b *Bitset) Not() *Bitset {
+	res := b.Clone()
+	for idx := range res.words {
+		res.words[idx] = ^res.words[idx]
+	}
+	res.trim()
+	return res
+}
+
+// Lsh shifts every bit up by `k`, like multiplying by 2^k. A fixed Bitset drops the
+// bits that go past the width, and a growable one gets `k` bits wider.
+func (b *Bitset) Lsh(k int) *Bitset {
+	width := b.width
+	if b.growable {
+		width += k
+	}
+	res := &Bitset{make([]uint64, n_words(width)), width, b.growable}
+	shift_words, shift_bits := k/64, uint(k%64)
+	for idx := range res.words {
+		src := idx - shift_words
+		if src < 0 {
+			continue
+		}
+		res.words[idx] = b.word(src) << shift_bits
+		if shift_bits != 0 && src > 0 {
+			res.words[idx] |= b.word(src-1) >> (64 - shift_bits)
+		}
+	}
+	res.trim()
+	return res
+}
+
+// Rsh shifts every bit down by `k`, like dividing by 2^k and rounding down. A growable
+// Bitset gets `k` bits narrower.
+func (b *Bitset) Rsh(k int) *Bitset {
+	width := b.width
+	if b.growable {
+		width = max(width-k, 0)
+	}
+	res := &Bitset{make([]uint64, n_words(width)), width, b.growable}
+	shift_words, shift_bits := k/64, uint(k%64)
+	for idx := range res.words {
+		src := idx + shift_words
+		res.words[idx] = b.word(src) >> shift_bits
+		if shift_bits != 0 {
+			res.words[idx] |= b.word(src+1) << (64 - shift_bits)
+		}
+	}
+	res.trim()
+	return res
+}
+
+// All yields each set bit, from lowest to highest
+func (b *Bitset) All() iter.Seq[int] {
+	return func(yield func(int) bool) {
+		for idx, w := range b.words {
+			for w != 0 {
+				bit := bits.TrailingZeros64(w)
+				if !yield(idx*64 + bit) {
+					return
+				}
+				// Clear the lowest set bit
+				w &= w - 1
+			}
+		}
+	}
+}
+
+// Uint64 returns the Bitset as a number. It returns false if a bit past 63 is set.
+func (b *Bitset) Uint64() (uint64, bool) {
+	for _, w := range b.words[min(1, len(b.words)):] {
+		if w != 0 {
+			return 0, false
+		}
+	}
+	return b.word(0), true
+}
+
+// Big returns the Bitset as a number of any size
+func (b *Bitset) Big() *big.Int {
+	n := new(big.Int)
+	for idx := len(b.words) - 1; idx >= 0; idx-- {
+		n.Lsh(n, 64)
+		n.Or(n, new(big.Int).SetUint64(b.words[idx]))
+	}
+	return n
+}
+
+// Bools returns one bool for each bit up to the width
+func (b *Bitset) Bools() []bool {
+	res := make([]bool, b.width)
+	for idx := range res {
+		res[idx] = b.Test(idx)
+	}
+	return res
+}
+
+// String writes the Bitset as a binary number, with the highest bit first and padded
+// with zeros to the width
+func (b *Bitset) String() string {
+	var sb strings.Builder
+	sb.Grow(b.width)
+	for idx := b.width - 1; idx >= 0; idx-- {
+		if b.Test(idx) {
+			sb.WriteByte('1')
+		} else {
+			sb.WriteByte('0')
+		}
+	}
+	return sb.String()
+}
+
+// Add reads both Bitsets as binary numbers and adds them. A fixed result wraps around
+// at its width, and a growable one gets wider to hold the carry.
+func (b *Bitset) Add(other *Bitset) *Bitset {
+	res := b.empty_like(other)
+	var carry uint64
+	for idx := range res.words {
+		res.words[idx], carry = bits.Add64(b.word(idx), other.word(idx), carry)
+	}
+
+	// The carry out of the top can land in the spare bits of the last word, or past it
+	if res.growable {
+		top := res.width
+		if top%64 != 0 && res.words[len(res.words)-1]>>(top%64) != 0 {
+			res.width++
+		} else if carry != 0 {
+			res.Set(top)
+		}
+	}
+	res.trim()
+	return res
+}
+
+// Sub reads both Bitsets as binary numbers and subtracts them, wrapping around at the
+// width of the result like unsigned integers do
+func (b *Bitset) Sub(other *Bitset) *Bitset {
+	res := b.empty_like(other)
+	var borrow uint64
+	for idx := range res.words {
+		res.words[idx], borrow = bits.Sub64(b.word(idx), other.word(idx), borrow)
+	}
+	res.trim()
+	return res
+}
+
+// HalfAdder treats each bit position as its own lane, and adds one bit from `a` to one
+// bit from `b` in every lane at once
+func HalfAdder(a, b *Bitset) (sum, carry *Bitset) {
+	return a.Xor(b), a.And(b)
+}
+
+// FullAdder treats each bit position as its own lane, and adds the bits of `a`, `b` and
+// a carry in from `c` in every lane at once. A ripple-carry adder is a chain of these,
+// which is what the day 24 circuit is meant to be.
+func FullAdder(a, b, c *Bitset) (sum, carry *Bitset) {
+	ab := a.Xor(b)
+	return ab.Xor(c), a.And(b).Or(ab.And(c))
+}
@@ -0,0 +1,181 @@
+package utils
+
+import (
+	"math/big"
+	"math/rand"
+	"slices"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// must_parse_bitset is ParseBitset for tests
+func must_parse_bitset(s string) *Bitset {
+	b, err := ParseBitset(s)
+	if err != nil {
+		panic(err)
+	}
+	return b
+}
+
+func TestBitsetBasics(t *testing.T) {
+	b := NewBitset(10)
+	assert.Equal(t, 10, b.Len())
+	assert.False(t, b.Growable())
+	assert.False(t, b.Any())
+
+	b.Set(0)
+	b.Set(3)
+	b.Set(9)
+	b.Flip(3)
+	b.Flip(4)
+	b.SetTo(5, true)
+	b.SetTo(0, false)
+	assert.Equal(t, "1000110000", b.String())
+	assert.Equal(t, 3, b.Count())
+	assert.True(t, b.Test(9))
+	assert.False(t, b.Test(10))
+	assert.False(t, b.Test(-1))
+	assert.Equal(t, []int{4, 5, 9}, slices.Collect(b.All()))
+	assert.Panics(t, func() { b.Set(10) })
+	assert.Panics(t, func() { b.Set(-1) })
+
+	// Clones don't share memory
+	c := b.Clone()
+	c.Clear(9)
+	assert.True(t, b.Test(9))
+
+	// Growable ones get wider
+	g := NewGrowableBitset()
+	g.Set(130)
+	assert.Equal(t, 131, g.Len())
+	assert.Equal(t, []int{130}, slices.Collect(g.All()))
+}
+
+func TestBitsetConversions(t *testing.T) {
+	b := BitsetFromUint64(0b1011, 6)
+	assert.Equal(t, "001011", b.String())
+	v, ok := b.Uint64()
+	assert.True(t, ok)
+	assert.Equal(t, uint64(11), v)
+	assert.Equal(t, []bool{true, true, false, true, false, false}, b.Bools())
+
+	// Bits past the width are dropped
+	assert.Equal(t, "1111", BitsetFromUint64(0xff, 4).String())
+
+	assert.True(t, b.Equal(BitsetFromBools([]bool{true, true, false, true})))
+	assert.True(t, b.Equal(must_parse_bitset("1011")))
+
+	_, err := ParseBitset("10a1")
+	assert.EqualError(t, err, `ParseBitset: invalid character 'a' at 2`)
+
+	// Round trip through big.Int
+	n, _ := new(big.Int).SetString("123456789012345678901234567890", 10)
+	big_b := BitsetFromBig(n, 0)
+	assert.Equal(t, n.BitLen(), big_b.Len())
+	assert.Equal(t, 0, n.Cmp(big_b.Big()))
+	assert.Equal(t, n.Text(2), big_b.String())
+	_, ok = big_b.Uint64()
+	assert.False(t, ok)
+
+	// Keeping only the lowest bits
+	assert.Equal(t, n.Text(2)[n.BitLen()-8:], BitsetFromBig(n, 8).String())
+}
+
+func TestBitsetLogic(t *testing.T) {
+	a := must_parse_bitset("1100")
+	b := must_parse_bitset("1010")
+	assert.Equal(t, "1000", a.And(b).String())
+	assert.Equal(t, "1110", a.Or(b).String())
+	assert.Equal(t, "0110", a.Xor(b).String())
+	assert.Equal(t, "0100", a.AndNot(b).String())
+	assert.Equal(t, "0011", a.Not().String())
+	assert.True(t, a.Intersects(b))
+	assert.False(t, a.Intersects(a.Not()))
+
+	// Different widths give the wider width
+	c := must_parse_bitset("1")
+	assert.Equal(t, "1101", a.Or(c).String())
+	assert.Equal(t, "1101", c.Or(a).String())
+}
+
+func TestBitsetShifts(t *testing.T) {
+	b := must_parse_bitset("10011")
+	assert.Equal(t, "01100", b.Lsh(2).String())
+	assert.Equal(t, "00100", b.Rsh(2).String())
+	assert.Equal(t, "00000", b.Lsh(5).String())
+
+	g := BitsetFromBig(big.NewInt(0b10011), 0)
+	assert.Equal(t, "1001100", g.Lsh(2).String())
+	assert.Equal(t, "100", g.Rsh(2).String())
+
+	// Across word boundaries, compared with big.Int. big.Int.Rand needs math/rand
+	// rather than math/rand/v2.
+	r := rand.New(rand.NewSource(34))
+	for range 200 {
+		n := new(big.Int).Rand(r, new(big.Int).Lsh(big.NewInt(1), 300))
+		k := r.Intn(200)
+		fixed := BitsetFromBig(n, 300)
+
+		want := new(big.Int).Lsh(n, uint(k))
+		want.Mod(want, new(big.Int).Lsh(big.NewInt(1), 300))
+		assert.Equal(t, 0, want.Cmp(fixed.Lsh(k).Big()), "lsh %d", k)
+		assert.Equal(t, 0, new(big.Int).Rsh(n, uint(k)).Cmp(fixed.Rsh(k).Big()), "rsh %d", k)
+
+		grow := BitsetFromBig(n, 0)
+		assert.Equal(t, 0, new(big.Int).Lsh(n, uint(k)).Cmp(grow.Lsh(k).Big()), "growable lsh %d", k)
+	}
+}
+
+func TestBitsetArithmetic(t *testing.T) {
+	r := rand.New(rand.NewSource(56))
+	for range 500 {
+		width := 1 + r.Intn(150)
+		limit := new(big.Int).Lsh(big.NewInt(1), uint(width))
+		x := new(big.Int).Rand(r, limit)
+		y := new(big.Int).Rand(r, limit)
+		bx, by := BitsetFromBig(x, width), BitsetFromBig(y, width)
+
+		// Fixed widths wrap around
+		sum := new(big.Int).Add(x, y)
+		assert.Equal(t, 0, new(big.Int).Mod(sum, limit).Cmp(bx.Add(by).Big()), "%v + %v", x, y)
+		diff := new(big.Int).Sub(x, y)
+		assert.Equal(t, 0, new(big.Int).Mod(diff, limit).Cmp(bx.Sub(by).Big()), "%v - %v", x, y)
+
+		// Growable ones keep the carry
+		gx, gy := BitsetFromBig(x, 0), BitsetFromBig(y, 0)
+		got := gx.Add(gy)
+		assert.Equal(t, 0, sum.Cmp(got.Big()), "%v + %v", x, y)
+		assert.Equal(t, max(sum.BitLen(), 1), max(got.Len(), 1), "%v + %v", x, y)
+	}
+}
+
+func TestAdders(t *testing.T) {
+	a := must_parse_bitset("01010101")
+	b := must_parse_bitset("00110011")
+	c := must_parse_bitset("00001111")
+
+	sum, carry := HalfAdder(a, b)
+	assert.Equal(t, "01100110", sum.String())
+	assert.Equal(t, "00010001", carry.String())
+
+	// Every lane is one row of the full adder truth table
+	sum, carry = FullAdder(a, b, c)
+	assert.Equal(t, "01101001", sum.String())
+	assert.Equal(t, "00010111", carry.String())
+
+	// Rippling the carry through the lanes gives the same answer as Add
+	x, y := BitsetFromUint64(0b1011_0110, 8), BitsetFromUint64(0b0110_1101, 8)
+	result := NewBitset(8)
+	carry_in := false
+	for lane := range 8 {
+		s, c := FullAdder(
+			BitsetFromBools([]bool{x.Test(lane)}),
+			BitsetFromBools([]bool{y.Test(lane)}),
+			BitsetFromBools([]bool{carry_in}),
+		)
+		result.SetTo(lane, s.Test(0))
+		carry_in = c.Test(0)
+	}
+	assert.True(t, x.Add(y).Equal(result))
+}
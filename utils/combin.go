@@ -0,0 +1,195 @@
+package utils
+
+import (
+	"cmp"
+	"iter"
+	"slices"
+)
+
+// The generators in this file hand out the same slice on every step, changing it in
+// place, so looping over them doesn't allocate. The slice is only good until the next
+// step: use slices.Clone to keep one. None of them change the input.
+
+// Permutations yields every ordering of `items` using Heap's algorithm, where each step
+// only swaps two items. The orderings are not in lexicographic order, and repeated items
+// give repeated orderings.
+func Permutations[T any](items []T) iter.Seq[[]T] {
+	return func(yield func([]T) bool) {
+		buf := slices.Clone(items)
+
+		// c[i] counts how many swaps have been done at depth i. This is the
+		// iterative form of Heap's algorithm.
+		c := make([]int, len(buf))
+		if !yield(buf) {
+			return
+		}
+
+		i := 1
+		for i < len(buf) {
+			if c[i] < i {
+				if i%2 == 0 {
+					buf[0], buf[i] = buf[i], buf[0]
+				} else {
+					buf[c[i]], buf[i] = buf[i], buf[c[i]]
+				}
+				if !yield(buf) {
+					return
+				}
+				c[i]++
+				i = 1
+			} else {
+				c[i] = 0
+				i++
+			}
+		}
+	}
+}
+
+// NextPermutation rearranges `s` in place into the next ordering in lexicographic
+// order. If `s` is already the last ordering, it sorts `s` back into the first one and
+// returns false.
+func NextPermutation[T cmp.Ordered](s []T) bool {
+	// Find the last place where the next item is bigger
+	i := len(s) - 2
+	for i >= 0 && s[i] >= s[i+1] {
+		i--
+	}
+	if i < 0 {
+		slices.Reverse(s)
+		return false
+	}
+
+	// Swap it with the last item bigger than it, and put the tail back in order
+	j := len(s) - 1
+	for s[j] <= s[i] {
+		j--
+	}
+	s[i], s[j] = s[j], s[i]
+	slices.Reverse(s[i+1:])
+	return true
+}
+
+// LexPermutations yields every distinct ordering of `items` in lexicographic order. Unlike
+// Permutations, repeated items don't give repeated orderings.
+func LexPermutations[T cmp.Ordered](items []T) iter.Seq[[]T] {
+	return func(yield func([]T) bool) {
+		buf := slices.Clone(items)
+		slices.Sort(buf)
+		for {
+			if !yield(buf) {
+				return
+			}
+			if !NextPermutation(buf) {
+				return
+			}
+		}
+	}
+}
+
+// Combinations yields every way to choose `k` of `items`, keeping them in the same order
+// as in `items`. The choices come in lexicographic order of their positions, e.g. for
+// [a b c] and k=2: [a b], [a c], [b c].
+func Combinations[T any](items []T, k int) iter.Seq[[]T] {
+	return func(yield func([]T) bool) {
+		n := len(items)
+		if k < 0 || k > n {
+			return
+		}
+
+		// idx holds the positions of the chosen items
+		idx := make([]int, k)
+		buf := make([]T, k)
+		for i := range k {
+			idx[i] = i
+			buf[i] = items[i]
+		}
+
+		for {
+			if !yield(buf) {
+				return
+			}
+
+			// Find the rightmost position that can still move right
+			i := k - 1
+			for i >= 0 && idx[i] == n-k+i {
+				i--
+			}
+			if i < 0 {
+				return
+			}
+
+			// Move it, and put everything after it right behind it
+			idx[i]++
+			buf[i] = items[idx[i]]
+			for j := i + 1; j < k; j++ {
+				idx[j] = idx[j-1] + 1
+				buf[j] = items[idx[j]]
+			}
+		}
+	}
+}
+
+// CartesianProduct yields every way to pick one item from each of `sets`, like nested
+// loops with the last set in the innermost loop. If any set is empty there are none.
+func CartesianProduct[T any](sets ...[]T) iter.Seq[[]T] {
+	return func(yield func([]T) bool) {
+		for _, s := range sets {
+			if len(s) == 0 {
+				return
+			}
+		}
+
+		idx := make([]int, len(sets))
+		buf := make([]T, len(sets))
+		for i, s := range sets {
+			buf[i] = s[0]
+		}
+
+		for {
+			if !yield(buf) {
+				return
+			}
+
+			// Count up like an odometer, with the last set turning fastest
+			i := len(sets) - 1
+			for i >= 0 {
+				idx[i]++
+				if idx[i] < len(sets[i]) {
+					buf[i] = sets[i][idx[i]]
+					break
+				}
+				idx[i] = 0
+				buf[i] = sets[i][0]
+				i--
+			}
+			if i < 0 {
+				return
+			}
+		}
+	}
+}
+
+// PowerSet yields every subset of `items`, starting with the empty set. Each subset
+// keeps the items in the same order as in `items`. Subsets come in order of the binary
+// number made by which items they include, with items[0] as the lowest bit.
+func PowerSet[T any](items []T) iter.Seq[[]T] {
+	return func(yield func([]T) bool) {
+		n := len(items)
+		if n >= 63 {
+			panic("PowerSet: too many items")
+		}
+
+		buf := make([]T, 0, n)
+		for mask := range uint64(1) << n {
+			buf = buf[:0]
+			for i := range n {
+				if mask&(1<<i) != 0 {
+					buf = append(buf, items[i])
+				}
+			}
+			if !yield(buf) {
+				return
+			}
+		}
+	}
+}
@@ -0,0 +1,160 @@
+package utils
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// collect_clones collects a generator that reuses its buffer
+func collect_clones[T any](seq func(func([]T) bool)) [][]T {
+	res := make([][]T, 0)
+	for s := range seq {
+		res = append(res, slices.Clone(s))
+	}
+	return res
+}
+
+func TestPermutations(t *testing.T) {
+	items := []int{1, 2, 3}
+	got := collect_clones(Permutations(items))
+	assert.Len(t, got, 6)
+	assert.ElementsMatch(t, [][]int{{1, 2, 3}, {1, 3, 2}, {2, 1, 3}, {2, 3, 1}, {3, 1, 2}, {3, 2, 1}}, got)
+	assert.Equal(t, []int{1, 2, 3}, items)
+
+	// Every ordering of 6 items is there once
+	seen := NewSet[string]()
+	for p := range Permutations([]byte("abcdef")) {
+		seen.Add(string(p))
+	}
+	assert.Len(t, seen, 720)
+
+	assert.Equal(t, [][]int{{}}, collect_clones(Permutations([]int{})))
+	assert.Equal(t, [][]int{{7}}, collect_clones(Permutations([]int{7})))
+}
+
+func TestNextPermutation(t *testing.T) {
+	s := []int{1, 2, 3}
+	var got [][]int
+	for {
+		got = append(got, slices.Clone(s))
+		if !NextPermutation(s) {
+			break
+		}
+	}
+	assert.Equal(t, [][]int{{1, 2, 3}, {1, 3, 2}, {2, 1, 3}, {2, 3, 1}, {3, 1, 2}, {3, 2, 1}}, got)
+
+	// Wraps back around to the first
+	assert.Equal(t, []int{1, 2, 3}, s)
+}
+
+func TestLexPermutations(t *testing.T) {
+	// Repeated items only give distinct orderings
+	got := collect_clones(LexPermutations([]byte("baa")))
+	assert.Equal(t, [][]byte{[]byte("aab"), []byte("aba"), []byte("baa")}, got)
+
+	count := 0
+	for range LexPermutations([]int{5, 4, 3, 2, 1}) {
+		count++
+	}
+	assert.Equal(t, 120, count)
+}
+
+func TestCombinations(t *testing.T) {
+	items := []string{"a", "b", "c", "d"}
+	got := collect_clones(Combinations(items, 2))
+	assert.Equal(t, [][]string{{"a", "b"}, {"a", "c"}, {"a", "d"}, {"b", "c"}, {"b", "d"}, {"c", "d"}}, got)
+
+	assert.Equal(t, [][]string{{}}, collect_clones(Combinations(items, 0)))
+	assert.Equal(t, [][]string{items}, collect_clones(Combinations(items, 4)))
+	assert.Empty(t, collect_clones(Combinations(items, 5)))
+	assert.Empty(t, collect_clones(Combinations(items, -1)))
+
+	// n choose k
+	count := 0
+	for range Combinations(make([]int, 20), 5) {
+		count++
+	}
+	assert.Equal(t, 15504, count)
+}
+
+func TestCartesianProduct(t *testing.T) {
+	got := collect_clones(CartesianProduct([]int{1, 2}, []int{3}, []int{4, 5}))
+	assert.Equal(t, [][]int{{1, 3, 4}, {1, 3, 5}, {2, 3, 4}, {2, 3, 5}}, got)
+
+	assert.Empty(t, collect_clones(CartesianProduct([]int{1, 2}, []int{})))
+	assert.Equal(t, [][]int{{}}, collect_clones(CartesianProduct[int]()))
+}
+
+func TestPowerSet(t *testing.T) {
+	got := collect_clones(PowerSet([]string{"a", "b", "c"}))
+	want := [][]string{{}, {"a"}, {"b"}, {"a", "b"}, {"c"}, {"a", "c"}, {"b", "c"}, {"a", "b", "c"}}
+	assert.Equal(t, want, got)
+	assert.Equal(t, [][]string{{}}, collect_clones(PowerSet([]string{})))
+}
+
+func TestCombinatoricsStopEarly(t *testing.T) {
+	items := []int{1, 2, 3, 4, 5}
+	seqs := map[string]func(func([]int) bool){
+		"Permutations":     Permutations(items),
+		"LexPermutations":  LexPermutations(items),
+		"Combinations":     Combinations(items, 3),
+		"CartesianProduct": CartesianProduct(items, items),
+		"PowerSet":         PowerSet(items),
+	}
+	for name, seq := range seqs {
+		t.Run(name, func(t *testing.T) {
+			count := 0
+			for range seq {
+				count++
+				if count == 3 {
+					break
+				}
+			}
+			assert.Equal(t, 3, count)
+		})
+	}
+}
+
+func TestCombinatoricsDontAllocatePerStep(t *testing.T) {
+	// Going through thousands of results allocates no more than stopping after the first
+	items := []byte("abcdefgh")
+	seqs := map[string]func(func([]byte) bool){
+		"Permutations":     Permutations(items),
+		"LexPermutations":  LexPermutations(items),
+		"Combinations":     Combinations(items, 4),
+		"CartesianProduct": CartesianProduct(items, items, items, items),
+		"PowerSet":         PowerSet(items),
+	}
+	for name, seq := range seqs {
+		t.Run(name, func(t *testing.T) {
+			first := testing.AllocsPerRun(10, func() {
+				for range seq {
+					break
+				}
+			})
+			all := testing.AllocsPerRun(10, func() {
+				for range seq {
+				}
+			})
+			assert.Equal(t, first, all)
+		})
+	}
+}
+
+func BenchmarkPermutations(b *testing.B) {
+	items := []int{1, 2, 3, 4, 5, 6, 7, 8, 9}
+	for b.Loop() {
+		for range Permutations(items) {
+		}
+	}
+}
+
+func BenchmarkLexPermutations(b *testing.B) {
+	items := []int{1, 2, 3, 4, 5, 6, 7, 8, 9}
+	for b.Loop() {
+		for range LexPermutations(items) {
+		}
+	}
+}
@@ -0,0 +1,111 @@
+package utils
+
+import (
+	"cmp"
+	"iter"
+	"maps"
+	"slices"
+)
+
+// Counter counts how many times each key has been seen, like a multiset. It is a plain
+// map underneath, so `c[key]` returns 0 for keys that have not been seen, and `len(c)`
+// is the number of distinct keys.
+type Counter[K comparable] map[K]int
+
+// KeyCount is a key and how many times it was counted
+type KeyCount[K comparable] struct {
+	Key   K
+	Count int
+}
+
+// NewCounter creates an empty counter, with room for `size` distinct keys
+func NewCounter[K comparable](size int) Counter[K] {
+	return make(Counter[K], size)
+}
+
+// CountAll counts every item in the sequence
+func CountAll[K comparable](items iter.Seq[K]) Counter[K] {
+	c := make(Counter[K])
+	for item := range items {
+		c[item] += 1
+	}
+	return c
+}
+
+// Add adds `n` to the count of `key`
+func (c Counter[K]) Add(key K, n int) {
+	c[key] += n
+}
+
+// Merge adds all of the counts in `other` to this counter
+func (c Counter[K]) Merge(other Counter[K]) {
+	for k, n := range other {
+		c[k] += n
+	}
+}
+
+// Sub takes all of the counts in `other` away from this counter. Counts never go below
+// zero, and any key whose count reaches zero is removed.
+func (c Counter[K]) Sub(other Counter[K]) {
+	for k, n := range other {
+		remaining, ok := c[k]
+		if !ok {
+			continue
+		}
+		if remaining <= n {
+			delete(c, k)
+		} else {
+			c[k] = remaining - n
+		}
+	}
+}
+
+// Total returns the sum of all the counts
+func (c Counter[K]) Total() int {
+	total := 0
+	for _, n := range c {
+		total += n
+	}
+	return total
+}
+
+// MostCommon returns the `n` keys with the highest counts, highest first. If n is
+// negative or more than the number of keys, all keys are returned. Keys with equal counts
+// are in no particular order.
+func (c Counter[K]) MostCommon(n int) []KeyCount[K] {
+	by_count := func(a, b KeyCount[K]) int { return cmp.Compare(b.Count, a.Count) }
+
+	// Want everything, so just sort it all
+	if n < 0 || n >= len(c) {
+		res := make([]KeyCount[K], 0, len(c))
+		for k, count := range c {
+			res = append(res, KeyCount[K]{k, count})
+		}
+		slices.SortFunc(res, by_count)
+		return res
+	}
+
+	// Otherwise keep the best n seen so far in a queue, where the smallest of them is
+	// first in line to be dropped
+	best := NewPriorityQueue(func(a, b KeyCount[K]) bool { return a.Count < b.Count })
+	for k, count := range c {
+		if best.Len() < n {
+			best.Push(KeyCount[K]{k, count})
+		} else if n > 0 && count > best.Peek().Count {
+			best.Pop()
+			best.Push(KeyCount[K]{k, count})
+		}
+	}
+
+	res := make([]KeyCount[K], 0, n)
+	for best.Len() > 0 {
+		res = append(res, best.Pop())
+	}
+	slices.Reverse(res)
+	return res
+}
+
+// Clone returns a copy of the counter
+func (c Counter[K]) Clone() Counter[K] {
+	return maps.Clone(c)
+}
@@ -0,0 +1,53 @@
+package utils
+
+import (
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCountAll(t *testing.T) {
+	c := CountAll(slices.Values(strings.Split("a b a c a b", " ")))
+	assert.Equal(t, Counter[string]{"a": 3, "b": 2, "c": 1}, c)
+	assert.Equal(t, 6, c.Total())
+	assert.Equal(t, 0, c["z"])
+}
+
+func TestCounterAddMerge(t *testing.T) {
+	c := NewCounter[int](4)
+	c.Add(1, 2)
+	c.Add(2, 1)
+	c.Add(1, 3)
+	assert.Equal(t, Counter[int]{1: 5, 2: 1}, c)
+
+	c.Merge(Counter[int]{2: 4, 3: 1})
+	assert.Equal(t, Counter[int]{1: 5, 2: 5, 3: 1}, c)
+	assert.Equal(t, 11, c.Total())
+
+	// Clones don't share anything
+	d := c.Clone()
+	d.Add(1, 1)
+	assert.Equal(t, 5, c[1])
+}
+
+func TestCounterSub(t *testing.T) {
+	c := Counter[string]{"a": 3, "b": 2, "c": 1}
+	c.Sub(Counter[string]{"a": 1, "b": 5, "z": 2})
+
+	// b is clamped at zero and removed, and z never shows up
+	assert.Equal(t, Counter[string]{"a": 2, "c": 1}, c)
+
+	c.Sub(Counter[string]{"a": 2, "c": 1})
+	assert.Empty(t, c)
+	assert.Equal(t, 0, c.Total())
+}
+
+func TestCounterMostCommon(t *testing.T) {
+	c := Counter[rune]{'a': 5, 'b': 1, 'c': 3, 'd': 4}
+	assert.Equal(t, []KeyCount[rune]{{'a', 5}, {'d', 4}}, c.MostCommon(2))
+	assert.Equal(t, []KeyCount[rune]{{'a', 5}, {'d', 4}, {'c', 3}, {'b', 1}}, c.MostCommon(-1))
+	assert.Len(t, c.MostCommon(10), 4)
+	assert.Empty(t, c.MostCommon(0))
+}
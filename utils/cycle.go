@@ -0,0 +1,105 @@
+package utils
+
+// Cycle describes a sequence x0, next(x0), next(next(x0)), ... that eventually repeats.
+// The first Tail states are never seen again, and after that the states go around a
+// loop of length Period forever.
+type Cycle struct {
+	Tail, Period int
+}
+
+// At returns the smallest step that has the same state as step `n`
+func (c Cycle) At(n int) int {
+	if n < c.Tail {
+		return n
+	}
+	return c.Tail + (n-c.Tail)%c.Period
+}
+
+// identity is the key function for states that can be compared directly
+func identity[S any](s S) S {
+	return s
+}
+
+// Floyd finds the cycle in the sequence start, next(start), ... with Floyd's tortoise
+// and hare. It only keeps two states at a time, but calls next about three times as
+// often as Brent. The sequence must repeat eventually, or it will never return.
+func Floyd[S comparable](start S, next func(S) S) Cycle {
+	return FloydFunc(start, next, identity[S])
+}
+
+// FloydFunc is Floyd for states that can't be compared directly. Two states are the
+// same if `key` gives the same value for both, e.g. a hash of the state.
+func FloydFunc[S any, K comparable](start S, next func(S) S, key func(S) K) Cycle {
+	// Find a step i where state i is the same as state 2i
+	tortoise, hare := next(start), next(next(start))
+	for key(tortoise) != key(hare) {
+		tortoise = next(tortoise)
+		hare = next(next(hare))
+	}
+
+	// i is a multiple of the period, so stepping from the start and from state i
+	// together, they first meet where the cycle starts
+	tail := 0
+	tortoise = start
+	for key(tortoise) != key(hare) {
+		tortoise = next(tortoise)
+		hare = next(hare)
+		tail++
+	}
+
+	// Go around once to find the period
+	period := 1
+	target := key(tortoise)
+	for hare = next(tortoise); key(hare) != target; hare = next(hare) {
+		period++
+	}
+	return Cycle{tail, period}
+}
+
+// Brent finds the cycle in the sequence start, next(start), ... with Brent's
+// algorithm. Like Floyd, it only keeps two states at a time, but it calls next fewer
+// times. The sequence must repeat eventually, or it will never return.
+func Brent[S comparable](start S, next func(S) S) Cycle {
+	return BrentFunc(start, next, identity[S])
+}
+
+// BrentFunc is Brent for states that can't be compared directly. Two states are the
+// same if `key` gives the same value for both, e.g. a hash of the state.
+func BrentFunc[S any, K comparable](start S, next func(S) S, key func(S) K) Cycle {
+	// Move the tortoise up to the hare at each power of two, until the hare runs into
+	// it. Then the distance between them is the period.
+	power, period := 1, 1
+	tortoise, hare := start, next(start)
+	tortoise_key := key(tortoise)
+	for tortoise_key != key(hare) {
+		if power == period {
+			tortoise, tortoise_key = hare, key(hare)
+			power *= 2
+			period = 0
+		}
+		hare = next(hare)
+		period++
+	}
+
+	// Start one period apart, and step together until they meet where the cycle starts
+	tortoise, hare = start, start
+	for range period {
+		hare = next(hare)
+	}
+	tail := 0
+	for key(tortoise) != key(hare) {
+		tortoise = next(tortoise)
+		hare = next(hare)
+		tail++
+	}
+	return Cycle{tail, period}
+}
+
+// FastForward returns the state after `n` steps from `start`, using the cycle to skip
+// over every full loop, so it calls next fewer than Tail + Period times.
+func FastForward[S any](start S, next func(S) S, c Cycle, n int) S {
+	for range c.At(n) {
+		start = next(start)
+	}
+	return start
+}
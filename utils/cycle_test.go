@@ -0,0 +1,97 @@
+package utils
+
+import (
+	"fmt"
+	"slices"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// brute_force_cycle finds the cycle by remembering every state
+func brute_force_cycle[S comparable](start S, next func(S) S) Cycle {
+	seen := make(map[S]int)
+	for step := 0; ; step++ {
+		if first, ok := seen[start]; ok {
+			return Cycle{first, step - first}
+		}
+		seen[start] = step
+		start = next(start)
+	}
+}
+
+func TestCycleDetection(t *testing.T) {
+	tests := []struct {
+		name  string
+		start int
+		next  func(int) int
+		want  Cycle
+	}{
+		{"no tail", 0, func(x int) int { return (x + 1) % 7 }, Cycle{0, 7}},
+		{"fixed point", 5, func(x int) int { return x }, Cycle{0, 1}},
+		{"tail into fixed point", 10, func(x int) int { return max(x-1, 3) }, Cycle{7, 1}},
+		{"rho", 1, func(x int) int {
+			if x < 20 {
+				return x + 1
+			}
+			return 12
+		}, Cycle{11, 9}},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.want, brute_force_cycle(tc.start, tc.next))
+			assert.Equal(t, tc.want, Floyd(tc.start, tc.next))
+			assert.Equal(t, tc.want, Brent(tc.start, tc.next))
+		})
+	}
+
+	// Pseudo-random maps have all sorts of tails and periods
+	for m := 2; m < 300; m += 7 {
+		for start := range 5 {
+			next := func(x int) int { return (x*x + 1) % m }
+			want := brute_force_cycle(start, next)
+			assert.Equal(t, want, Floyd(start, next), "m=%d start=%d", m, start)
+			assert.Equal(t, want, Brent(start, next), "m=%d start=%d", m, start)
+		}
+	}
+}
+
+func TestCycleDetectionKey(t *testing.T) {
+	// Slices can't be compared, so use a string key. Rotating 5 items takes 5 steps to
+	// get back to the start.
+	next := func(s []int) []int { return append(slices.Clone(s[1:]), s[0]) }
+	key := func(s []int) string { return fmt.Sprint(s) }
+	want := Cycle{0, 5}
+	assert.Equal(t, want, FloydFunc([]int{1, 2, 3, 4, 5}, next, key))
+	assert.Equal(t, want, BrentFunc([]int{1, 2, 3, 4, 5}, next, key))
+}
+
+func TestFastForward(t *testing.T) {
+	next := func(x int) int { return (x*x + 1) % 1009 }
+	c := Brent(3, next)
+
+	for _, n := range []int{0, 1, c.Tail, c.Tail + 1, c.Tail + c.Period, 1000, 12345} {
+		want := 3
+		for range n {
+			want = next(want)
+		}
+		assert.Equal(t, want, FastForward(3, next, c, n), "n=%d", n)
+	}
+
+	// Far past anything that could be stepped through one at a time
+	assert.Equal(t, FastForward(3, next, c, c.At(1e18)), FastForward(3, next, c, 1e18))
+}
+
+func BenchmarkFloyd(b *testing.B) {
+	next := func(x int) int { return (x*x + 1) % 1_000_003 }
+	for b.Loop() {
+		Floyd(3, next)
+	}
+}
+
+func BenchmarkBrent(b *testing.B) {
+	next := func(x int) int { return (x*x + 1) % 1_000_003 }
+	for b.Loop() {
+		Brent(3, next)
+	}
+}
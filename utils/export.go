@@ -0,0 +1,340 @@
+package utils
+
+import (
+	"cmp"
+	"fmt"
+	"io"
+	"maps"
+	"slices"
+	"strings"
+)
+
+// ExportOptions controls how WriteDOT and WriteMermaid draw a graph. The zero value
+// draws every node labelled with fmt.Sprint(node), with no clusters or highlights.
+type ExportOptions[T comparable] struct {
+	// Name of the graph. Only used by DOT, and defaults to "G"
+	Name string
+
+	// Directed draws edges as arrows instead of plain lines
+	Directed bool
+
+	// Label returns the text to show for a node. Defaults to fmt.Sprint(node)
+	Label func(T) string
+
+	// NodeAttrs returns any extra attributes for a node, e.g. {"shape": "box"}. In DOT
+	// these are node attributes, in Mermaid they are written as a `style` line, so use
+	// CSS-like keys such as "fill" or "stroke" there.
+	NodeAttrs func(T) map[string]string
+
+	// HighlightNodes and HighlightEdges are drawn in HighlightColor. For undirected
+	// graphs an edge is highlighted no matter which order its nodes are given in.
+	HighlightNodes map[T]struct{}
+	HighlightEdges map[[2]T]struct{}
+
+	// HighlightColor defaults to "red"
+	HighlightColor string
+
+	// Cluster returns the name of the group a node belongs to. Nodes in the same group
+	// are drawn inside a box together. An empty string means no group.
+	Cluster func(T) string
+}
+
+// ClusterByPrefix returns a Cluster function for ExportOptions that puts each node in the
+// group of the first prefix its fmt.Sprint form starts with. E.g. ClusterByPrefix("x",
+// "y", "z") puts the day 24 input and output wires in their own boxes.
+func ClusterByPrefix[T comparable](prefixes ...string) func(T) string {
+	return func(n T) string {
+		s := fmt.Sprint(n)
+		for _, p := range prefixes {
+			if strings.HasPrefix(s, p) {
+				return p
+			}
+		}
+		return ""
+	}
+}
+
+// export_graph is the sorted, numbered form of a graph shared by both writers. Each node
+// gets the ID `n<index>`, so that node names never have to be escaped.
+type export_graph[T comparable] struct {
+	nodes  []T
+	labels []string
+	ids    map[T]int
+	edges  [][2]T
+}
+
+// prep_export sorts the nodes by label (then by their fmt.Sprint form), and the edges by
+// the index of their nodes, so that the output is the same from one call to the next.
+// Any node only seen in `edges` is added to the nodes.
+func prep_export[T comparable](nodes []T, edges [][2]T, opts ExportOptions[T]) export_graph[T] {
+	label := opts.Label
+	if label == nil {
+		label = func(n T) string { return fmt.Sprint(n) }
+	}
+
+	// Collect every node, including any that only show up in an edge
+	all_nodes := make(map[T]struct{}, len(nodes))
+	for _, n := range nodes {
+		all_nodes[n] = struct{}{}
+	}
+	for _, e := range edges {
+		all_nodes[e[0]] = struct{}{}
+		all_nodes[e[1]] = struct{}{}
+	}
+
+	eg := export_graph[T]{ids: make(map[T]int, len(all_nodes))}
+	eg.nodes = slices.SortedFunc(maps.Keys(all_nodes), func(a, b T) int {
+		return cmp.Or(
+			strings.Compare(label(a), label(b)),
+			strings.Compare(fmt.Sprint(a), fmt.Sprint(b)),
+		)
+	})
+	eg.labels = make([]string, len(eg.nodes))
+	for idx, n := range eg.nodes {
+		eg.ids[n] = idx
+		eg.labels[idx] = label(n)
+	}
+
+	eg.edges = slices.Clone(edges)
+	slices.SortStableFunc(eg.edges, func(a, b [2]T) int {
+		return cmp.Or(
+			cmp.Compare(eg.ids[a[0]], eg.ids[b[0]]),
+			cmp.Compare(eg.ids[a[1]], eg.ids[b[1]]),
+		)
+	})
+
+	return eg
+}
+
+// clusters groups the node indices by cluster name. Names are returned sorted, and the
+// nodes with no cluster are under the empty string.
+func (eg export_graph[T]) clusters(opts ExportOptions[T]) ([]string, map[string][]int) {
+	groups := make(map[string][]int)
+	for idx, n := range eg.nodes {
+		name := ""
+		if opts.Cluster != nil {
+			name = opts.Cluster(n)
+		}
+		groups[name] = append(groups[name], idx)
+	}
+	return slices.Sorted(maps.Keys(groups)), groups
+}
+
+// edge_highlighted checks if an edge is in opts.HighlightEdges, in either direction if
+// the graph is undirected.
+func edge_highlighted[T comparable](e [2]T, opts ExportOptions[T]) bool {
+	if _, ok := opts.HighlightEdges[e]; ok {
+		return true
+	}
+	if opts.Directed {
+		return false
+	}
+	_, ok := opts.HighlightEdges[[2]T{e[1], e[0]}]
+	return ok
+}
+
+// dot_quote puts a string in double quotes, escaping anything that DOT would choke on
+func dot_quote(s string) string {
+	s = strings.ReplaceAll(s, `\`, `\\`)
+	s = strings.ReplaceAll(s, `"`, `\"`)
+	s = strings.ReplaceAll(s, "\n", `\n`)
+	return `"` + s + `"`
+}
+
+// dot_attrs writes out attributes in the DOT form ` [a="1", b="2"]`, sorted by key
+func dot_attrs(attrs map[string]string) string {
+	if len(attrs) == 0 {
+		return ""
+	}
+	parts := make([]string, 0, len(attrs))
+	for _, k := range slices.Sorted(maps.Keys(attrs)) {
+		parts = append(parts, k+"="+dot_quote(attrs[k]))
+	}
+	return " [" + strings.Join(parts, ", ") + "]"
+}
+
+// WriteDOT writes the graph made up of `nodes` and `edges` in the Graphviz DOT language.
+// Render it with something like `dot -Tsvg graph.dot > graph.svg`.
+func WriteDOT[T comparable](w io.Writer, nodes []T, edges [][2]T, opts ExportOptions[T]) error {
+	eg := prep_export(nodes, edges, opts)
+	color := cmp.Or(opts.HighlightColor, "red")
+	name := cmp.Or(opts.Name, "G")
+
+	graph_kind, edge_op := "graph", "--"
+	if opts.Directed {
+		graph_kind, edge_op = "digraph", "->"
+	}
+
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "%s %s {\n", graph_kind, dot_quote(name))
+
+	// The nodes, inside their clusters
+	names, groups := eg.clusters(opts)
+	for _, cluster := range names {
+		indent := "  "
+		if cluster != "" {
+			fmt.Fprintf(&sb, "  subgraph %s {\n", dot_quote("cluster_"+cluster))
+			fmt.Fprintf(&sb, "    label=%s;\n", dot_quote(cluster))
+			indent = "    "
+		}
+		for _, idx := range groups[cluster] {
+			n := eg.nodes[idx]
+			attrs := map[string]string{"label": eg.labels[idx]}
+			if opts.NodeAttrs != nil {
+				maps.Copy(attrs, opts.NodeAttrs(n))
+			}
+			if _, ok := opts.HighlightNodes[n]; ok {
+				attrs["color"] = color
+				attrs["penwidth"] = "2"
+			}
+			fmt.Fprintf(&sb, "%sn%d%s;\n", indent, idx, dot_attrs(attrs))
+		}
+		if cluster != "" {
+			sb.WriteString("  }\n")
+		}
+	}
+
+	// The edges
+	for _, e := range eg.edges {
+		attrs := map[string]string{}
+		if edge_highlighted(e, opts) {
+			attrs["color"] = color
+			attrs["penwidth"] = "2"
+		}
+		fmt.Fprintf(&sb, "  n%d %s n%d%s;\n", eg.ids[e[0]], edge_op, eg.ids[e[1]], dot_attrs(attrs))
+	}
+	sb.WriteString("}\n")
+
+	_, err := io.WriteString(w, sb.String())
+	return err
+}
+
+// mermaid_quote puts a label in double quotes, using Mermaid's entity code for any
+// double quotes inside it
+func mermaid_quote(s string) string {
+	return `"` + strings.ReplaceAll(s, `"`, "#quot;") + `"`
+}
+
+// mermaid_style turns a node attribute into a `key:value` part of a `style` line. Commas
+// separate the parts, so any inside the value are escaped. A `;` or a line break would end
+// the statement, and CSS has no way to escape them, so those are an error.
+func mermaid_style(key, value string) (string, error) {
+	for _, s := range []string{key, value} {
+		if strings.ContainsAny(s, ";\r\n") {
+			return "", fmt.Errorf("node attribute %q: %q can't contain ';' or a line break", key, value)
+		}
+	}
+	if strings.ContainsAny(key, ":,") {
+		return "", fmt.Errorf("node attribute %q can't contain ':' or ','", key)
+	}
+	return key + ":" + strings.ReplaceAll(value, ",", "\\,"), nil
+}
+
+// WriteMermaid writes the graph made up of `nodes` and `edges` as a Mermaid flowchart,
+// which GitHub and many markdown viewers will draw inside a ```mermaid block. It returns
+// an error if a node attribute can't be written as Mermaid style.
+func WriteMermaid[T comparable](w io.Writer, nodes []T, edges [][2]T, opts ExportOptions[T]) error {
+	eg := prep_export(nodes, edges, opts)
+	color := cmp.Or(opts.HighlightColor, "red")
+
+	edge_op := "---"
+	if opts.Directed {
+		edge_op = "-->"
+	}
+
+	var sb strings.Builder
+	sb.WriteString("flowchart LR\n")
+
+	// The nodes, inside their clusters
+	names, groups := eg.clusters(opts)
+	for _, cluster := range names {
+		indent := "  "
+		if cluster != "" {
+			fmt.Fprintf(&sb, "  subgraph cluster_%d [%s]\n", slices.Index(names, cluster), mermaid_quote(cluster))
+			indent = "    "
+		}
+		for _, idx := range groups[cluster] {
+			fmt.Fprintf(&sb, "%sn%d[%s]\n", indent, idx, mermaid_quote(eg.labels[idx]))
+		}
+		if cluster != "" {
+			sb.WriteString("  end\n")
+		}
+	}
+
+	// The edges. Mermaid refers to edges by the order they were written in, so keep
+	// track of which ones need highlighting.
+	highlighted_edges := make([]string, 0)
+	for idx, e := range eg.edges {
+		fmt.Fprintf(&sb, "  n%d %s n%d\n", eg.ids[e[0]], edge_op, eg.ids[e[1]])
+		if edge_highlighted(e, opts) {
+			highlighted_edges = append(highlighted_edges, fmt.Sprint(idx))
+		}
+	}
+
+	// Any extra styling for the nodes
+	if opts.NodeAttrs != nil {
+		for idx, n := range eg.nodes {
+			attrs := opts.NodeAttrs(n)
+			if len(attrs) == 0 {
+				continue
+			}
+			parts := make([]string, 0, len(attrs))
+			for _, k := range slices.Sorted(maps.Keys(attrs)) {
+				part, err := mermaid_style(k, attrs[k])
+				if err != nil {
+					return err
+				}
+				parts = append(parts, part)
+			}
+			fmt.Fprintf(&sb, "  style n%d %s\n", idx, strings.Join(parts, ","))
+		}
+	}
+
+	// The highlighted nodes and edges
+	highlighted_nodes := make([]string, 0)
+	for idx, n := range eg.nodes {
+		if _, ok := opts.HighlightNodes[n]; ok {
+			highlighted_nodes = append(highlighted_nodes, fmt.Sprintf("n%d", idx))
+		}
+	}
+	if len(highlighted_nodes) > 0 {
+		fmt.Fprintf(&sb, "  classDef highlight stroke:%s,stroke-width:2px\n", color)
+		fmt.Fprintf(&sb, "  class %s highlight\n", strings.Join(highlighted_nodes, ","))
+	}
+	if len(highlighted_edges) > 0 {
+		fmt.Fprintf(&sb, "  linkStyle %s stroke:%s,stroke-width:2px\n", strings.Join(highlighted_edges, ","), color)
+	}
+
+	_, err := io.WriteString(w, sb.String())
+	return err
+}
+
+// Nodes returns every node in the graph, in no particular order
+func (g *DiGraph[T]) Nodes() []T {
+	return slices.Collect(maps.Keys(g.from_to))
+}
+
+// Edges returns every `{from, to}` edge in the graph, in no particular order
+func (g *DiGraph[T]) Edges() [][2]T {
+	edges := make([][2]T, 0, len(g.from_to))
+	for from, tos := range g.from_to {
+		for _, to := range tos {
+			edges = append(edges, [2]T{from, to})
+		}
+	}
+	return edges
+}
+
+// WriteDOT writes the graph in the Graphviz DOT language. Note that TopoSort removes
+// edges from the graph, so export before sorting.
+func (g *DiGraph[T]) WriteDOT(w io.Writer, opts ExportOptions[T]) error {
+	opts.Directed = true
+	return WriteDOT(w, g.Nodes(), g.Edges(), opts)
+}
+
+// WriteMermaid writes the graph as a Mermaid flowchart. Note that TopoSort removes edges
+// from the graph, so export before sorting.
+func (g *DiGraph[T]) WriteMermaid(w io.Writer, opts ExportOptions[T]) error {
+	opts.Directed = true
+	return WriteMermaid(w, g.Nodes(), g.Edges(), opts)
+}
@@ -0,0 +1,185 @@
+package utils
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// diamond builds the graph
+// ┌──A──┐
+// ▼     ▼
+// B     C
+// │     │
+// │     ▼
+// └────►D
+func diamond() DiGraph[string] {
+	g := NewDiGraph[string]()
+	g.AddEdge("A", "B")
+	g.AddEdge("A", "C")
+	g.AddEdge("B", "D")
+	g.AddEdge("C", "D")
+	return g
+}
+
+func TestWriteDOT(t *testing.T) {
+	g := diamond()
+	var sb strings.Builder
+	err := g.WriteDOT(&sb, ExportOptions[string]{})
+	assert.NoError(t, err)
+
+	want := `digraph "G" {
+  n0 [label="A"];
+  n1 [label="B"];
+  n2 [label="C"];
+  n3 [label="D"];
+  n0 -> n1;
+  n0 -> n2;
+  n1 -> n3;
+  n2 -> n3;
+}
+`
+	assert.Equal(t, want, sb.String())
+}
+
+func TestWriteDOTHighlightAndCluster(t *testing.T) {
+	g := NewDiGraph[string]()
+	g.AddEdge("x00", "abc")
+	g.AddEdge("y00", "abc")
+	g.AddEdge("abc", "z00")
+
+	opts := ExportOptions[string]{
+		Name:           "day24",
+		Label:          strings.ToUpper,
+		NodeAttrs:      func(n string) map[string]string { return map[string]string{"shape": "box"} },
+		HighlightNodes: map[string]struct{}{"abc": {}},
+		HighlightEdges: map[[2]string]struct{}{{"abc", "z00"}: {}},
+		HighlightColor: "blue",
+		Cluster:        ClusterByPrefix[string]("x", "y", "z"),
+	}
+	var sb strings.Builder
+	err := g.WriteDOT(&sb, opts)
+	assert.NoError(t, err)
+
+	want := `digraph "day24" {
+  n0 [color="blue", label="ABC", penwidth="2", shape="box"];
+  subgraph "cluster_x" {
+    label="x";
+    n1 [label="X00", shape="box"];
+  }
+  subgraph "cluster_y" {
+    label="y";
+    n2 [label="Y00", shape="box"];
+  }
+  subgraph "cluster_z" {
+    label="z";
+    n3 [label="Z00", shape="box"];
+  }
+  n0 -> n3 [color="blue", penwidth="2"];
+  n1 -> n0;
+  n2 -> n0;
+}
+`
+	assert.Equal(t, want, sb.String())
+}
+
+func TestWriteDOTUndirected(t *testing.T) {
+	nodes := []string{"a", "b", "lonely"}
+	edges := [][2]string{{"b", "a"}, {"c", "a"}}
+	opts := ExportOptions[string]{
+		// Undirected edges are highlighted in either direction
+		HighlightEdges: map[[2]string]struct{}{{"a", "b"}: {}},
+	}
+	var sb strings.Builder
+	err := WriteDOT(&sb, nodes, edges, opts)
+	assert.NoError(t, err)
+
+	want := `graph "G" {
+  n0 [label="a"];
+  n1 [label="b"];
+  n2 [label="c"];
+  n3 [label="lonely"];
+  n1 -- n0 [color="red", penwidth="2"];
+  n2 -- n0;
+}
+`
+	assert.Equal(t, want, sb.String())
+}
+
+func TestWriteMermaid(t *testing.T) {
+	g := diamond()
+	opts := ExportOptions[string]{
+		NodeAttrs: func(n string) map[string]string {
+			if n == "A" {
+				return map[string]string{"fill": "#eee"}
+			}
+			return nil
+		},
+		HighlightNodes: map[string]struct{}{"B": {}, "D": {}},
+		HighlightEdges: map[[2]string]struct{}{{"B", "D"}: {}},
+		Cluster: func(n string) string {
+			if n == "B" || n == "C" {
+				return `mid "layer"`
+			}
+			return ""
+		},
+	}
+	var sb strings.Builder
+	err := g.WriteMermaid(&sb, opts)
+	assert.NoError(t, err)
+
+	want := `flowchart LR
+  n0["A"]
+  n3["D"]
+  subgraph cluster_1 ["mid #quot;layer#quot;"]
+    n1["B"]
+    n2["C"]
+  end
+  n0 --> n1
+  n0 --> n2
+  n1 --> n3
+  n2 --> n3
+  style n0 fill:#eee
+  classDef highlight stroke:red,stroke-width:2px
+  class n1,n3 highlight
+  linkStyle 2 stroke:red,stroke-width:2px
+`
+	assert.Equal(t, want, sb.String())
+}
+
+func TestWriteMermaidStyleValues(t *testing.T) {
+	g := diamond()
+	style := func(attrs map[string]string) ExportOptions[string] {
+		return ExportOptions[string]{NodeAttrs: func(n string) map[string]string {
+			if n == "A" {
+				return attrs
+			}
+			return nil
+		}}
+	}
+
+	// Commas inside a value are escaped, so they don't split it into two parts
+	var sb strings.Builder
+	assert.NoError(t, g.WriteMermaid(&sb, style(map[string]string{"fill": "rgb(1,2,3)", "color": "#fff"})))
+	assert.Contains(t, sb.String(), "  style n0 color:#fff,fill:rgb(1\\,2\\,3)\n")
+
+	// Anything that would end the statement early is an error, and nothing is written
+	for _, attrs := range []map[string]string{
+		{"fill": "red;stroke:blue"},
+		{"fill": "red\n  click n0 evil"},
+		{"fill": "red\r"},
+		{"fi;ll": "red"},
+		{"fill:x": "red"},
+	} {
+		sb.Reset()
+		assert.Error(t, g.WriteMermaid(&sb, style(attrs)), attrs)
+		assert.Empty(t, sb.String())
+	}
+}
+
+func TestDiGraphNodesAndEdges(t *testing.T) {
+	g := diamond()
+	assert.ElementsMatch(t, []string{"A", "B", "C", "D"}, g.Nodes())
+	assert.ElementsMatch(t, [][2]string{{"A", "B"}, {"A", "C"}, {"B", "D"}, {"C", "D"}}, g.Edges())
+}
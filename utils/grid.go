@@ -0,0 +1,285 @@
+package utils
+
+import (
+	"fmt"
+	"iter"
+	"strings"
+)
+
+// Grid is a rectangular 2D board of cells. The cells are stored row by row in a single
+// flat slice, so the cell at (row, col) is at index `row*Width + col`. Rows increase
+// going down, and columns increase going right.
+type Grid[T any] struct {
+	cells []T
+
+	// The number of columns
+	Width int
+
+	// The number of rows
+	Height int
+}
+
+// NewGrid creates a grid of the given size, where every cell is the zero value.
+func NewGrid[T any](width, height int) Grid[T] {
+	return Grid[T]{
+		cells:  make([]T, width*height),
+		Width:  width,
+		Height: height,
+	}
+}
+
+// ParseGrid turns text made up of lines of equal length into a grid, converting each
+// character with `conv`. Leading and trailing whitespace, and any `\r` at the end of a
+// line, are ignored. It panics if the lines are not all the same length.
+func ParseGrid[T any](raw_text string, conv func(rune) T) Grid[T] {
+	lines := strings.Split(strings.TrimSpace(raw_text), "\n")
+	width := len([]rune(strings.TrimRight(lines[0], "\r")))
+
+	g := Grid[T]{
+		cells:  make([]T, 0, width*len(lines)),
+		Width:  width,
+		Height: len(lines),
+	}
+	for row, line := range lines {
+		line = strings.TrimRight(line, "\r")
+		n_cols := 0
+		for _, r := range line {
+			g.cells = append(g.cells, conv(r))
+			n_cols += 1
+		}
+		if n_cols != width {
+			panic(fmt.Sprintf("row %d has %d columns, expected %d", row, n_cols, width))
+		}
+	}
+	return g
+}
+
+// InBounds checks if (row, col) is inside the grid
+func (g Grid[T]) InBounds(row, col int) bool {
+	return row >= 0 && row < g.Height && col >= 0 && col < g.Width
+}
+
+// Index converts (row, col) into an index into the flat list of cells
+func (g Grid[T]) Index(row, col int) int {
+	return row*g.Width + col
+}
+
+// RowCol converts an index into the flat list of cells back into (row, col)
+func (g Grid[T]) RowCol(idx int) (int, int) {
+	return idx / g.Width, idx % g.Width
+}
+
+// At returns the value at (row, col). It panics if (row, col) is out of bounds.
+func (g Grid[T]) At(row, col int) T {
+	if !g.InBounds(row, col) {
+		panic(fmt.Sprintf("(%d, %d) is outside of the %dx%d grid", row, col, g.Height, g.Width))
+	}
+	return g.cells[g.Index(row, col)]
+}
+
+// Get returns the value at (row, col), and false if (row, col) is out of bounds.
+func (g Grid[T]) Get(row, col int) (T, bool) {
+	if !g.InBounds(row, col) {
+		var zero T
+		return zero, false
+	}
+	return g.cells[g.Index(row, col)], true
+}
+
+// Set stores `val` at (row, col). It panics if (row, col) is out of bounds.
+func (g Grid[T]) Set(row, col int, val T) {
+	if !g.InBounds(row, col) {
+		panic(fmt.Sprintf("(%d, %d) is outside of the %dx%d grid", row, col, g.Height, g.Width))
+	}
+	g.cells[g.Index(row, col)] = val
+}
+
+// Fill sets every cell to `val`
+func (g Grid[T]) Fill(val T) {
+	for idx := range g.cells {
+		g.cells[idx] = val
+	}
+}
+
+// Clone returns a copy of the grid that does not share any cells with the original
+func (g Grid[T]) Clone() Grid[T] {
+	cells := make([]T, len(g.cells))
+	copy(cells, g.cells)
+	return Grid[T]{cells, g.Width, g.Height}
+}
+
+// All iterates over every cell, row by row, yielding the flat index and the value. Use
+// RowCol to get the (row, col) of the index.
+func (g Grid[T]) All() iter.Seq2[int, T] {
+	return func(yield func(int, T) bool) {
+		for idx, val := range g.cells {
+			if !yield(idx, val) {
+				return
+			}
+		}
+	}
+}
+
+// neighbors yields the (row, col) of every in-bounds cell one step away in each of
+// `dirs`. A direction's Y is the change in row, and its X the change in column.
+func (g Grid[T]) neighbors(row, col int, dirs []Vec2) iter.Seq2[int, int] {
+	return func(yield func(int, int) bool) {
+		for _, d := range dirs {
+			r, c := row+d.Y, col+d.X
+			if !g.InBounds(r, c) {
+				continue
+			}
+			if !yield(r, c) {
+				return
+			}
+		}
+	}
+}
+
+// Neighbors4 yields the (row, col) of the up, right, down, and left neighbors of (row,
+// col) that are inside the grid
+func (g Grid[T]) Neighbors4(row, col int) iter.Seq2[int, int] {
+	return g.neighbors(row, col, Dirs4[:])
+}
+
+// Neighbors8 yields the (row, col) of all eight neighbors of (row, col) that are inside
+// the grid, going clockwise from up
+func (g Grid[T]) Neighbors8(row, col int) iter.Seq2[int, int] {
+	return g.neighbors(row, col, Dirs8[:])
+}
+
+// Walk yields the values starting at (row, col) and taking steps of (d_row, d_col) until
+// it goes off the edge of the grid.
+func (g Grid[T]) Walk(row, col, d_row, d_col int) iter.Seq[T] {
+	return func(yield func(T) bool) {
+		for g.InBounds(row, col) {
+			if !yield(g.cells[g.Index(row, col)]) {
+				return
+			}
+			if d_row == 0 && d_col == 0 {
+				return
+			}
+			row += d_row
+			col += d_col
+		}
+	}
+}
+
+// Row returns the cells in a row. Note that this shares memory with the grid, so
+// changing it will change the grid. It panics if the row is out of bounds.
+func (g Grid[T]) Row(row int) []T {
+	if row < 0 || row >= g.Height {
+		panic(fmt.Sprintf("row %d is outside of the %dx%d grid", row, g.Height, g.Width))
+	}
+	return g.cells[row*g.Width : (row+1)*g.Width]
+}
+
+// Rows yields the index and cells of each row, top to bottom. Like Row, each row shares
+// memory with the grid.
+func (g Grid[T]) Rows() iter.Seq2[int, []T] {
+	return func(yield func(int, []T) bool) {
+		for row := range g.Height {
+			if !yield(row, g.Row(row)) {
+				return
+			}
+		}
+	}
+}
+
+// Col returns a copy of the cells in a column, top to bottom. It panics if the column is
+// out of bounds.
+func (g Grid[T]) Col(col int) []T {
+	if col < 0 || col >= g.Width {
+		panic(fmt.Sprintf("column %d is outside of the %dx%d grid", col, g.Height, g.Width))
+	}
+	res := make([]T, g.Height)
+	for row := range g.Height {
+		res[row] = g.cells[g.Index(row, col)]
+	}
+	return res
+}
+
+// Diagonal returns a copy of the cells going down and to the right where `col - row ==
+// d`. d = 0 is the main diagonal, starting at the top left corner.
+func (g Grid[T]) Diagonal(d int) []T {
+	res := make([]T, 0)
+	for row := max(0, -d); row < g.Height && row+d < g.Width; row++ {
+		res = append(res, g.cells[g.Index(row, row+d)])
+	}
+	return res
+}
+
+// AntiDiagonal returns a copy of the cells going down and to the left where `row + col
+// == d`. d = 0 is just the top left corner.
+func (g Grid[T]) AntiDiagonal(d int) []T {
+	res := make([]T, 0)
+	for row := max(0, d-g.Width+1); row < g.Height && row <= d; row++ {
+		res = append(res, g.cells[g.Index(row, d-row)])
+	}
+	return res
+}
+
+// FindAll returns the (row, col) of every cell where `pred` is true, row by row
+func (g Grid[T]) FindAll(pred func(T) bool) [][2]int {
+	res := make([][2]int, 0)
+	for idx, val := range g.cells {
+		if pred(val) {
+			row, col := g.RowCol(idx)
+			res = append(res, [2]int{row, col})
+		}
+	}
+	return res
+}
+
+// Transpose returns a new grid where rows are swapped with columns
+func (g Grid[T]) Transpose() Grid[T] {
+	res := NewGrid[T](g.Height, g.Width)
+	for idx, val := range g.cells {
+		row, col := g.RowCol(idx)
+		res.cells[res.Index(col, row)] = val
+	}
+	return res
+}
+
+// RotateCW returns a new grid turned 90 degrees clockwise
+func (g Grid[T]) RotateCW() Grid[T] {
+	return g.Transpose().FlipH()
+}
+
+// RotateCCW returns a new grid turned 90 degrees counter-clockwise
+func (g Grid[T]) RotateCCW() Grid[T] {
+	return g.Transpose().FlipV()
+}
+
+// FlipH returns a new grid mirrored left to right
+func (g Grid[T]) FlipH() Grid[T] {
+	res := NewGrid[T](g.Width, g.Height)
+	for idx, val := range g.cells {
+		row, col := g.RowCol(idx)
+		res.cells[res.Index(row, g.Width-1-col)] = val
+	}
+	return res
+}
+
+// FlipV returns a new grid mirrored top to bottom
+func (g Grid[T]) FlipV() Grid[T] {
+	res := NewGrid[T](g.Width, g.Height)
+	for idx, val := range g.cells {
+		row, col := g.RowCol(idx)
+		res.cells[res.Index(g.Height-1-row, col)] = val
+	}
+	return res
+}
+
+// Sprint draws the grid with one line per row, using `cell` to turn each value into
+// text.
+func (g Grid[T]) Sprint(cell func(T) string) string {
+	var sb strings.Builder
+	for row := range g.Height {
+		for _, val := range g.Row(row) {
+			sb.WriteString(cell(val))
+		}
+		sb.WriteByte('\n')
+	}
+	return sb.String()
+}
@@ -0,0 +1,156 @@
+package utils
+
+import (
+	"slices"
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// to_byte is the simplest conversion for ParseGrid
+func to_byte(r rune) byte { return byte(r) }
+
+const small_grid = `abc
+def
+`
+
+func TestParseGrid(t *testing.T) {
+	g := ParseGrid(small_grid, to_byte)
+	assert.Equal(t, 3, g.Width)
+	assert.Equal(t, 2, g.Height)
+	assert.Equal(t, []byte("abcdef"), g.cells)
+
+	// Windows line endings are ignored
+	g = ParseGrid("ab\r\ncd\r\n", to_byte)
+	assert.Equal(t, []byte("abcd"), g.cells)
+
+	assert.Panics(t, func() { ParseGrid("abc\nde", to_byte) })
+}
+
+func TestGridAtSet(t *testing.T) {
+	g := ParseGrid(small_grid, to_byte)
+	assert.Equal(t, byte('a'), g.At(0, 0))
+	assert.Equal(t, byte('f'), g.At(1, 2))
+
+	g.Set(1, 1, 'X')
+	assert.Equal(t, byte('X'), g.At(1, 1))
+
+	v, ok := g.Get(1, 3)
+	assert.False(t, ok)
+	assert.Equal(t, byte(0), v)
+	assert.Panics(t, func() { g.At(2, 0) })
+	assert.Panics(t, func() { g.Set(-1, 0, 'a') })
+
+	row, col := g.RowCol(g.Index(1, 2))
+	assert.Equal(t, [2]int{1, 2}, [2]int{row, col})
+}
+
+func TestGridNeighbors(t *testing.T) {
+	g := NewGrid[int](3, 3)
+
+	collect := func(seq func(func(int, int) bool)) [][2]int {
+		res := make([][2]int, 0)
+		for r, c := range seq {
+			res = append(res, [2]int{r, c})
+		}
+		return res
+	}
+
+	assert.Equal(t, [][2]int{{0, 1}, {1, 2}, {2, 1}, {1, 0}}, collect(g.Neighbors4(1, 1)))
+	assert.Equal(t, [][2]int{{0, 1}, {1, 0}}, collect(g.Neighbors4(0, 0)))
+	assert.Len(t, collect(g.Neighbors8(1, 1)), 8)
+	assert.Equal(t, [][2]int{{0, 1}, {1, 1}, {1, 0}}, collect(g.Neighbors8(0, 0)))
+	assert.Equal(t, [][2]int{{1, 2}, {2, 1}, {1, 1}}, collect(g.Neighbors8(2, 2)))
+}
+
+func TestGridWalk(t *testing.T) {
+	g := ParseGrid("abc\ndef\nghi", to_byte)
+	assert.Equal(t, []byte("aei"), slices.Collect(g.Walk(0, 0, 1, 1)))
+	assert.Equal(t, []byte("fed"), slices.Collect(g.Walk(1, 2, 0, -1)))
+	assert.Equal(t, []byte("e"), slices.Collect(g.Walk(1, 1, 0, 0)))
+	assert.Equal(t, []byte(nil), slices.Collect(g.Walk(3, 0, 1, 0)))
+}
+
+func TestGridRowsColsDiagonals(t *testing.T) {
+	g := ParseGrid("abc\ndef", to_byte)
+	assert.Equal(t, []byte("def"), g.Row(1))
+	assert.Equal(t, []byte("cf"), g.Col(2))
+
+	// Out of range rows and columns panic, rather than reading into a neighbouring row
+	assert.PanicsWithValue(t, "row 2 is outside of the 2x3 grid", func() { g.Row(2) })
+	assert.PanicsWithValue(t, "row -1 is outside of the 2x3 grid", func() { g.Row(-1) })
+	assert.PanicsWithValue(t, "column 3 is outside of the 2x3 grid", func() { g.Col(3) })
+	assert.PanicsWithValue(t, "column -1 is outside of the 2x3 grid", func() { g.Col(-1) })
+
+	assert.Equal(t, []byte("ae"), g.Diagonal(0))
+	assert.Equal(t, []byte("bf"), g.Diagonal(1))
+	assert.Equal(t, []byte("c"), g.Diagonal(2))
+	assert.Equal(t, []byte("d"), g.Diagonal(-1))
+	assert.Equal(t, []byte{}, g.Diagonal(3))
+
+	assert.Equal(t, []byte("a"), g.AntiDiagonal(0))
+	assert.Equal(t, []byte("bd"), g.AntiDiagonal(1))
+	assert.Equal(t, []byte("ce"), g.AntiDiagonal(2))
+	assert.Equal(t, []byte("f"), g.AntiDiagonal(3))
+
+	n_rows := 0
+	for idx, row := range g.Rows() {
+		assert.Equal(t, g.Row(idx), row)
+		n_rows += 1
+	}
+	assert.Equal(t, 2, n_rows)
+}
+
+func TestGridTransforms(t *testing.T) {
+	g := ParseGrid("abc\ndef", to_byte)
+	tests := []struct {
+		name string
+		got  Grid[byte]
+		want string
+	}{
+		{"transpose", g.Transpose(), "ad\nbe\ncf\n"},
+		{"cw", g.RotateCW(), "da\neb\nfc\n"},
+		{"ccw", g.RotateCCW(), "cf\nbe\nad\n"},
+		{"flip h", g.FlipH(), "cba\nfed\n"},
+		{"flip v", g.FlipV(), "def\nabc\n"},
+		{"four turns", g.RotateCW().RotateCW().RotateCW().RotateCW(), "abc\ndef\n"},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.want, tc.got.Sprint(byte_cell))
+		})
+	}
+}
+
+func TestGridFindAll(t *testing.T) {
+	g := ParseGrid("#.#\n.##", to_byte)
+	got := g.FindAll(func(b byte) bool { return b == '#' })
+	assert.Equal(t, [][2]int{{0, 0}, {0, 2}, {1, 1}, {1, 2}}, got)
+}
+
+// byte_cell draws a byte as the character it is
+func byte_cell(b byte) string {
+	return string(rune(b))
+}
+
+func TestGridSprint(t *testing.T) {
+	b := NewGrid[bool](2, 2)
+	b.Set(0, 1, true)
+	got := b.Sprint(func(v bool) string {
+		if v {
+			return "#"
+		}
+		return "."
+	})
+	assert.Equal(t, ".#\n..\n", got)
+
+	// Numbers are drawn however the caller says, even when they are runes underneath
+	n := NewGrid[int32](3, 1)
+	n.Fill(7)
+	assert.Equal(t, "777\n", n.Sprint(func(v int32) string { return strconv.Itoa(int(v)) }))
+
+	c := n.Clone()
+	c.Set(0, 0, 1)
+	assert.Equal(t, int32(7), n.At(0, 0))
+}
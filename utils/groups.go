@@ -0,0 +1,146 @@
+package utils
+
+import (
+	"iter"
+	"regexp"
+	"regexp/syntax"
+	"unicode/utf8"
+)
+
+// GetGroups is essentially the same as FindAllStringSubmatch, but it returns
+// just the groups and not the full match
+func GetGroups(re *regexp.Regexp, s string) [][]string {
+	matches := re.FindAllStringSubmatch(s, -1)
+	for idx, match := range matches {
+		matches[idx] = match[1:]
+	}
+	return matches
+}
+
+// Match is a single match of a regex, with access to its capture groups by number or by
+// name
+type Match struct {
+	re  *regexp.Regexp
+	s   string
+	loc []int
+}
+
+// Group returns the text of capture group `i`, where group 0 is the whole match. An
+// optional group that did not take part in the match is "".
+func (m Match) Group(i int) string {
+	if m.loc[2*i] < 0 {
+		return ""
+	}
+	return m.s[m.loc[2*i]:m.loc[2*i+1]]
+}
+
+// Groups returns the text of every capture group, not including the whole match
+func (m Match) Groups() []string {
+	groups := make([]string, m.re.NumSubexp())
+	for idx := range groups {
+		groups[idx] = m.Group(idx + 1)
+	}
+	return groups
+}
+
+// Named returns the text of the capture group called `name`. It returns false if there
+// is no group with that name, or if it is an optional group that did not take part in the
+// match.
+func (m Match) Named(name string) (string, bool) {
+	idx := m.re.SubexpIndex(name)
+	if idx < 0 || m.loc[2*idx] < 0 {
+		return "", false
+	}
+	return m.Group(idx), true
+}
+
+// Start and End are the byte offsets of the whole match in the searched string
+func (m Match) Start() int { return m.loc[0] }
+func (m Match) End() int   { return m.loc[1] }
+
+// AllMatches finds the same matches as FindAllStringSubmatch, but hands them out one at a
+// time, so nothing is found past the point where the loop stops.
+func AllMatches(re *regexp.Regexp, s string) iter.Seq[Match] {
+	return func(yield func(Match) bool) {
+		// Searching a slice of s makes anchors like ^ and \b think the slice is the
+		// start of the text. Regexes that use them have to search the whole string.
+		if needs_context(re) {
+			for _, loc := range re.FindAllStringSubmatchIndex(s, -1) {
+				if !yield(Match{re, s, loc}) {
+					return
+				}
+			}
+			return
+		}
+
+		// The same rules for empty matches as the regexp package: step forward one rune
+		// after one, and don't allow one straight after the previous match.
+		pos, prev_end := 0, -1
+		for pos <= len(s) {
+			loc := re.FindStringSubmatchIndex(s[pos:])
+			if loc == nil {
+				return
+			}
+			for idx := range loc {
+				if loc[idx] >= 0 {
+					loc[idx] += pos
+				}
+			}
+
+			accept := true
+			if loc[1] == pos {
+				if loc[0] == prev_end {
+					accept = false
+				}
+				if pos < len(s) {
+					_, width := utf8.DecodeRuneInString(s[pos:])
+					pos += width
+				} else {
+					pos++
+				}
+			} else {
+				pos = loc[1]
+			}
+			prev_end = loc[1]
+
+			if accept && !yield(Match{re, s, loc}) {
+				return
+			}
+		}
+	}
+}
+
+// AllGroups is the streaming version of GetGroups
+func AllGroups(re *regexp.Regexp, s string) iter.Seq[[]string] {
+	return func(yield func([]string) bool) {
+		for m := range AllMatches(re, s) {
+			if !yield(m.Groups()) {
+				return
+			}
+		}
+	}
+}
+
+// needs_context says whether a regex has an assertion that looks at the text before
+// where the search starts
+func needs_context(re *regexp.Regexp) bool {
+	parsed, err := syntax.Parse(re.String(), syntax.Perl)
+	if err != nil {
+		return true
+	}
+
+	var walk func(r *syntax.Regexp) bool
+	walk = func(r *syntax.Regexp) bool {
+		switch r.Op {
+		case syntax.OpBeginLine, syntax.OpBeginText, syntax.OpWordBoundary, syntax.OpNoWordBoundary:
+			return true
+		}
+		for _, sub := range r.Sub {
+			if walk(sub) {
+				return true
+			}
+		}
+		return false
+	}
+	return walk(parsed)
+}
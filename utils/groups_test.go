@@ -0,0 +1,90 @@
+package utils
+
+import (
+	"regexp"
+	"slices"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGetGroups(t *testing.T) {
+	re := regexp.MustCompile(`mul\((\d+),(\d+)\)`)
+	got := GetGroups(re, "xmul(2,4)%&mul[3,7]!mul(32,64]then(mul(11,8)")
+	assert.Equal(t, [][]string{{"2", "4"}, {"11", "8"}}, got)
+
+	// Optional groups that don't match are empty strings, but still there
+	re = regexp.MustCompile(`(\w)=(\d+)(?:,(\d+))?`)
+	got = GetGroups(re, "a=1,2 b=3")
+	assert.Equal(t, [][]string{{"a", "1", "2"}, {"b", "3", ""}}, got)
+
+	assert.Empty(t, GetGroups(re, "nothing here"))
+}
+
+func TestAllGroups(t *testing.T) {
+	re := regexp.MustCompile(`(\w)=(\d+)(?:,(\d+))?`)
+	got := slices.Collect(AllGroups(re, "a=1,2 b=3 c=45,6"))
+	assert.Equal(t, [][]string{{"a", "1", "2"}, {"b", "3", ""}, {"c", "45", "6"}}, got)
+
+	// Stopping early
+	count := 0
+	for groups := range AllGroups(re, "a=1,2 b=3 c=45,6") {
+		assert.Equal(t, []string{"a", "1", "2"}, groups)
+		count++
+		break
+	}
+	assert.Equal(t, 1, count)
+}
+
+func TestAllMatchesNamed(t *testing.T) {
+	re := regexp.MustCompile(`(?P<name>\w+)=(?P<val>\d+)(?:\.(?P<frac>\d+))?`)
+	var names, vals, fracs []string
+	for m := range AllMatches(re, "x=1.5, yy=22") {
+		name, ok := m.Named("name")
+		assert.True(t, ok)
+		names = append(names, name)
+		vals = append(vals, m.Group(2))
+		frac, ok := m.Named("frac")
+		if ok {
+			fracs = append(fracs, frac)
+		}
+
+		_, ok = m.Named("missing")
+		assert.False(t, ok)
+	}
+	assert.Equal(t, []string{"x", "yy"}, names)
+	assert.Equal(t, []string{"1", "22"}, vals)
+	assert.Equal(t, []string{"5"}, fracs)
+}
+
+func TestAllMatchesSameAsRegexp(t *testing.T) {
+	tests := []struct {
+		pattern string
+		input   string
+	}{
+		{`a*`, "baaab"},
+		{`x*`, "héllo"},
+		{`(a)|b`, "abcab"},
+		{`^\d+`, "12 34\n56"},
+		{`(?m)^\d+`, "12 34\n56"},
+		{`\bab`, "ab cab ab"},
+		{`\Bab`, "ab cab ab"},
+		{`\d+$`, "1 2 3"},
+		{``, "abc"},
+	}
+	for _, tc := range tests {
+		t.Run(tc.pattern, func(t *testing.T) {
+			re := regexp.MustCompile(tc.pattern)
+			want := re.FindAllStringSubmatchIndex(tc.input, -1)
+			got := make([][]int, 0)
+			for m := range AllMatches(re, tc.input) {
+				got = append(got, m.loc)
+			}
+			if want == nil {
+				assert.Empty(t, got)
+			} else {
+				assert.Equal(t, want, got)
+			}
+		})
+	}
+}
@@ -0,0 +1,164 @@
+package utils
+
+import (
+	"fmt"
+	"math"
+	"math/big"
+	"strconv"
+	"strings"
+)
+
+// IntOptions changes how ExtractInts and friends read numbers
+type IntOptions struct {
+	// Treat every '-' as a separator instead of a minus sign
+	Unsigned bool
+}
+
+// IntOverflowError says which number in the text does not fit in an int
+type IntOverflowError struct {
+	// The number as it appears in the text, including any minus sign
+	Text string
+
+	// The byte offset of the number in the text
+	Offset int
+}
+
+func (e *IntOverflowError) Error() string {
+	return fmt.Sprintf("integer %s at offset %d does not fit in an int", e.Text, e.Offset)
+}
+
+func (e *IntOverflowError) Unwrap() error {
+	return strconv.ErrRange
+}
+
+// is_digit is faster than unicode.IsDigit, and only accepts ASCII digits
+func is_digit(c byte) bool {
+	return '0' <= c && c <= '9'
+}
+
+// scan_ints calls `yield` with the start and end of every run of digits in `s`, where
+// the start includes the minus sign if there is one. A '-' is only a minus sign if it is
+// right before a digit and not right after one, so "3-5" is 3 and 5, but "x=-3" is -3.
+func scan_ints(s string, opts IntOptions, yield func(start, end int) bool) {
+	idx := 0
+	for idx < len(s) {
+		if !is_digit(s[idx]) {
+			idx++
+			continue
+		}
+
+		start := idx
+		if !opts.Unsigned && start > 0 && s[start-1] == '-' && (start < 2 || !is_digit(s[start-2])) {
+			start--
+		}
+		for idx < len(s) && is_digit(s[idx]) {
+			idx++
+		}
+		if !yield(start, idx) {
+			return
+		}
+	}
+}
+
+// parse_int_token converts a run of digits with an optional leading '-' into an int,
+// returning false if it is too big
+func parse_int_token(tok string) (int, bool) {
+	neg := tok[0] == '-'
+	if neg {
+		tok = tok[1:]
+	}
+
+	// Keep the size as a uint64 so that math.MinInt can be read without overflowing
+	limit := uint64(math.MaxInt)
+	if neg {
+		limit++
+	}
+
+	var n uint64
+	for idx := range len(tok) {
+		d := uint64(tok[idx] - '0')
+		if n > (limit-d)/10 {
+			return 0, false
+		}
+		n = n*10 + d
+	}
+
+	if neg {
+		return int(-n), true
+	}
+	return int(n), true
+}
+
+// ExtractInts finds every integer in `s`, skipping over anything that isn't a digit, so
+// "p=-3,4 v=10" gives [-3 4 10]. It returns an *IntOverflowError if a number does not fit
+// in an int, so callers know to switch to ExtractBigInts, or use ExtractIntsOrBig to do
+// that for them.
+func ExtractInts(s string, opts IntOptions) ([]int, error) {
+	res := make([]int, 0)
+	var err error
+	scan_ints(s, opts, func(start, end int) bool {
+		n, ok := parse_int_token(s[start:end])
+		if !ok {
+			err = &IntOverflowError{s[start:end], start}
+			return false
+		}
+		res = append(res, n)
+		return true
+	})
+	if err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+// ExtractIntsPerLine is ExtractInts for each line of `s`. Offsets in errors are still
+// from the start of `s`.
+func ExtractIntsPerLine(s string, opts IntOptions) ([][]int, error) {
+	lines := strings.Split(strings.TrimRight(s, "\n"), "\n")
+	res := make([][]int, len(lines))
+	offset := 0
+	for idx, line := range lines {
+		nums, err := ExtractInts(line, opts)
+		if err != nil {
+			if oe, ok := err.(*IntOverflowError); ok {
+				oe.Offset += offset
+			}
+			return nil, err
+		}
+		res[idx] = nums
+		offset += len(line) + 1
+	}
+	return res, nil
+}
+
+// ExtractBigInts is ExtractInts for numbers of any size
+func ExtractBigInts(s string, opts IntOptions) []*big.Int {
+	res := make([]*big.Int, 0)
+	scan_ints(s, opts, func(start, end int) bool {
+		n, _ := new(big.Int).SetString(s[start:end], 10)
+		res = append(res, n)
+		return true
+	})
+	return res
+}
+
+// ExtractIntsOrBig is ExtractInts that falls back to big.Int instead of failing. If every
+// number fits in an int, they are returned as ints and the big.Int slice is nil.
+// Otherwise the int slice is nil and every number is returned as a big.Int, so the
+// numbers stay in order. The text is only scanned a second time if a number overflows.
+func ExtractIntsOrBig(s string, opts IntOptions) ([]int, []*big.Int) {
+	res, err := ExtractInts(s, opts)
+	if err == nil {
+		return res, nil
+	}
+	return nil, ExtractBigInts(s, opts)
+}
+
+// Ints is ExtractInts with signed numbers, and panics if a number is too big
+func Ints(s string) []int {
+	res, err := ExtractInts(s, IntOptions{})
+	if err != nil {
+		panic(err)
+	}
+	return res
+}
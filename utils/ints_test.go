@@ -0,0 +1,127 @@
+package utils
+
+import (
+	"errors"
+	"math"
+	"math/big"
+	"regexp"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestExtractInts(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		unsigned bool
+		want     []int
+	}{
+		{"robot", "p=0,4 v=3,-3", false, []int{0, 4, 3, -3}},
+		{"robot unsigned", "p=0,4 v=3,-3", true, []int{0, 4, 3, 3}},
+		{"button", "Button A: X+94, Y+34", false, []int{94, 34}},
+		{"range", "3-5 10--2", false, []int{3, 5, 10, -2}},
+		{"leading minus", "-7 -0", false, []int{-7, 0}},
+		{"lone minus", "a - b -", false, []int{}},
+		{"no numbers", "", false, []int{}},
+		{"limits", "9223372036854775807 -9223372036854775808", false, []int{math.MaxInt, math.MinInt}},
+		{"leading zeros", "007", false, []int{7}},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := ExtractInts(tc.input, IntOptions{Unsigned: tc.unsigned})
+			assert.NoError(t, err)
+			assert.Equal(t, tc.want, got)
+		})
+	}
+}
+
+func TestExtractIntsOverflow(t *testing.T) {
+	tests := []struct {
+		input    string
+		unsigned bool
+		text     string
+		offset   int
+	}{
+		{"1 9223372036854775808", false, "9223372036854775808", 2},
+		{"x=-9223372036854775809", false, "-9223372036854775809", 2},
+		{"x=-9223372036854775808", true, "9223372036854775808", 3},
+		{"99999999999999999999999", false, "99999999999999999999999", 0},
+	}
+	for _, tc := range tests {
+		t.Run(tc.input, func(t *testing.T) {
+			_, err := ExtractInts(tc.input, IntOptions{Unsigned: tc.unsigned})
+			var oe *IntOverflowError
+			assert.True(t, errors.As(err, &oe))
+			assert.Equal(t, tc.text, oe.Text)
+			assert.Equal(t, tc.offset, oe.Offset)
+			assert.ErrorIs(t, err, strconv.ErrRange)
+		})
+	}
+
+	assert.Panics(t, func() { Ints("123456789012345678901234567890") })
+}
+
+func TestExtractIntsPerLine(t *testing.T) {
+	got, err := ExtractIntsPerLine("7 6 4 2 1\n1 2 7 8 9\n\n-3\n", IntOptions{})
+	assert.NoError(t, err)
+	assert.Equal(t, [][]int{{7, 6, 4, 2, 1}, {1, 2, 7, 8, 9}, {}, {-3}}, got)
+
+	// The offset counts from the start of the whole text
+	_, err = ExtractIntsPerLine("1 2\n3 99999999999999999999", IntOptions{})
+	var oe *IntOverflowError
+	assert.True(t, errors.As(err, &oe))
+	assert.Equal(t, 6, oe.Offset)
+}
+
+func TestExtractBigInts(t *testing.T) {
+	got := ExtractBigInts("a=-123456789012345678901234567890,b=5", IntOptions{})
+	want, _ := new(big.Int).SetString("-123456789012345678901234567890", 10)
+	assert.Len(t, got, 2)
+	assert.Equal(t, 0, want.Cmp(got[0]))
+	assert.Equal(t, int64(5), got[1].Int64())
+
+	got = ExtractBigInts("a=-12", IntOptions{Unsigned: true})
+	assert.Equal(t, int64(12), got[0].Int64())
+}
+
+func TestExtractIntsOrBig(t *testing.T) {
+	// Everything fits, so there are no big.Ints
+	ints, bigs := ExtractIntsOrBig("p=-3,4 v=10", IntOptions{})
+	assert.Equal(t, []int{-3, 4, 10}, ints)
+	assert.Nil(t, bigs)
+
+	// One number overflows, so they all come back as big.Ints, in order
+	ints, bigs = ExtractIntsOrBig("1 -99999999999999999999999 2", IntOptions{})
+	assert.Nil(t, ints)
+	got := make([]string, len(bigs))
+	for i, b := range bigs {
+		got[i] = b.String()
+	}
+	assert.Equal(t, []string{"1", "-99999999999999999999999", "2"}, got)
+
+	// The options still apply
+	_, bigs = ExtractIntsOrBig("-99999999999999999999999", IntOptions{Unsigned: true})
+	assert.Equal(t, "99999999999999999999999", bigs[0].String())
+}
+
+var bench_ints_input = strings.Repeat("p=62,65 v=-96,-93\n", 500)
+
+func BenchmarkExtractInts(b *testing.B) {
+	for b.Loop() {
+		Ints(bench_ints_input)
+	}
+}
+
+func BenchmarkExtractIntsRegex(b *testing.B) {
+	re := regexp.MustCompile(`-?\d+`)
+	for b.Loop() {
+		matches := re.FindAllString(bench_ints_input, -1)
+		nums := make([]int, len(matches))
+		for idx, m := range matches {
+			nums[idx] = ParseInt(m)
+		}
+	}
+}
@@ -0,0 +1,227 @@
+package utils
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"math/big"
+)
+
+// ErrSingular is returned when a system of equations does not have exactly one solution
+var ErrSingular = errors.New("matrix is singular")
+
+// ErrNoIntegerSolution is returned when the only solution of a system is not all integers
+var ErrNoIntegerSolution = errors.New("solution is not all integers")
+
+// ErrSolutionOverflow is returned when the solution is all integers, but one of them is
+// too big for an int. SolveBig can find it.
+var ErrSolutionOverflow = errors.New("solution does not fit in an int")
+
+// checked_sub returns a - b, and false if that overflows
+func checked_sub(a, b int) (int, bool) {
+	c := a - b
+	if (a >= 0 && b < 0 && c < 0) || (a < 0 && b > 0 && c >= 0) {
+		return 0, false
+	}
+	return c, true
+}
+
+// checked_mul returns a * b, and false if that overflows
+func checked_mul(a, b int) (int, bool) {
+	if a == 0 || b == 0 {
+		return 0, true
+	}
+	c := a * b
+	if c/b != a || (a == -1 && b == math.MinInt) || (b == -1 && a == math.MinInt) {
+		return 0, false
+	}
+	return c, true
+}
+
+// check_system makes sure `a` is square and `b` is the same height
+func check_system(n_rows, n_b int, row_len func(int) int) error {
+	if n_rows != n_b {
+		return fmt.Errorf("matrix has %d rows but there are %d values", n_rows, n_b)
+	}
+	for idx := range n_rows {
+		if row_len(idx) != n_rows {
+			return fmt.Errorf("row %d has %d columns, but the matrix must be %dx%d", idx, row_len(idx), n_rows, n_rows)
+		}
+	}
+	return nil
+}
+
+// SolveInt solves the system of equations a·x = b exactly, for a square matrix `a`. It
+// returns ErrSingular if there is not exactly one solution, and ErrNoIntegerSolution if
+// that solution is not all integers.
+//
+// It uses fraction-free (Bareiss) elimination, so nothing is rounded. If any number
+// along the way overflows an int, it starts again with math/big.
+func SolveInt(a [][]int, b []int) ([]int, error) {
+	if err := check_system(len(a), len(b), func(i int) int { return len(a[i]) }); err != nil {
+		return nil, err
+	}
+
+	x, ok, err := solve_int(a, b)
+	if ok {
+		return x, err
+	}
+
+	// Something overflowed, so do it again with math/big
+	big_x, err := SolveBig(to_big_matrix(a), to_big_slice(b))
+	if err != nil {
+		return nil, err
+	}
+	x = make([]int, len(big_x))
+	for idx, v := range big_x {
+		if !v.IsInt64() {
+			return nil, ErrSolutionOverflow
+		}
+		x[idx] = int(v.Int64())
+	}
+	return x, nil
+}
+
+// solve_int is SolveInt with ints only. It returns false if anything overflowed.
+func solve_int(a [][]int, b []int) ([]int, bool, error) {
+	n := len(a)
+
+	// Build the augmented matrix [a | b], so the input isn't changed
+	m := make([][]int, n)
+	for i := range n {
+		m[i] = make([]int, n+1)
+		copy(m[i], a[i])
+		m[i][n] = b[i]
+	}
+
+	prev := 1
+	for k := range n {
+		// Find a row with a non-zero pivot
+		if m[k][k] == 0 {
+			swap := -1
+			for i := k + 1; i < n; i++ {
+				if m[i][k] != 0 {
+					swap = i
+					break
+				}
+			}
+			if swap == -1 {
+				return nil, true, ErrSingular
+			}
+			m[k], m[swap] = m[swap], m[k]
+		}
+
+		// Each new value is a 2x2 determinant, which always divides exactly by the
+		// previous pivot
+		for i := k + 1; i < n; i++ {
+			for j := k + 1; j <= n; j++ {
+				l, ok1 := checked_mul(m[i][j], m[k][k])
+				r, ok2 := checked_mul(m[i][k], m[k][j])
+				d, ok3 := checked_sub(l, r)
+				if !ok1 || !ok2 || !ok3 {
+					return nil, false, nil
+				}
+				m[i][j] = d / prev
+			}
+			m[i][k] = 0
+		}
+		prev = m[k][k]
+	}
+
+	// Back substitution. If the solution is all integers, every division is exact.
+	x := make([]int, n)
+	for i := n - 1; i >= 0; i-- {
+		sum := m[i][n]
+		for j := i + 1; j < n; j++ {
+			p, ok1 := checked_mul(m[i][j], x[j])
+			s, ok2 := checked_sub(sum, p)
+			if !ok1 || !ok2 {
+				return nil, false, nil
+			}
+			sum = s
+		}
+		if sum%m[i][i] != 0 {
+			return nil, true, ErrNoIntegerSolution
+		}
+		x[i] = sum / m[i][i]
+	}
+	return x, true, nil
+}
+
+// SolveBig is SolveInt for numbers of any size. The inputs are not changed.
+func SolveBig(a [][]*big.Int, b []*big.Int) ([]*big.Int, error) {
+	if err := check_system(len(a), len(b), func(i int) int { return len(a[i]) }); err != nil {
+		return nil, err
+	}
+	n := len(a)
+
+	m := make([][]*big.Int, n)
+	for i := range n {
+		m[i] = make([]*big.Int, n+1)
+		for j := range n {
+			m[i][j] = new(big.Int).Set(a[i][j])
+		}
+		m[i][n] = new(big.Int).Set(b[i])
+	}
+
+	prev := big.NewInt(1)
+	l, r := new(big.Int), new(big.Int)
+	for k := range n {
+		if m[k][k].Sign() == 0 {
+			swap := -1
+			for i := k + 1; i < n; i++ {
+				if m[i][k].Sign() != 0 {
+					swap = i
+					break
+				}
+			}
+			if swap == -1 {
+				return nil, ErrSingular
+			}
+			m[k], m[swap] = m[swap], m[k]
+		}
+
+		for i := k + 1; i < n; i++ {
+			for j := k + 1; j <= n; j++ {
+				l.Mul(m[i][j], m[k][k])
+				r.Mul(m[i][k], m[k][j])
+				m[i][j].Quo(l.Sub(l, r), prev)
+			}
+			m[i][k].SetInt64(0)
+		}
+		prev = m[k][k]
+	}
+
+	x := make([]*big.Int, n)
+	rem := new(big.Int)
+	for i := n - 1; i >= 0; i-- {
+		sum := new(big.Int).Set(m[i][n])
+		for j := i + 1; j < n; j++ {
+			sum.Sub(sum, l.Mul(m[i][j], x[j]))
+		}
+		sum.QuoRem(sum, m[i][i], rem)
+		if rem.Sign() != 0 {
+			return nil, ErrNoIntegerSolution
+		}
+		x[i] = sum
+	}
+	return x, nil
+}
+
+// to_big_matrix copies a matrix of ints into big.Ints
+func to_big_matrix(a [][]int) [][]*big.Int {
+	res := make([][]*big.Int, len(a))
+	for idx, row := range a {
+		res[idx] = to_big_slice(row)
+	}
+	return res
+}
+
+// to_big_slice copies a slice of ints into big.Ints
+func to_big_slice(a []int) []*big.Int {
+	res := make([]*big.Int, len(a))
+	for idx, v := range a {
+		res[idx] = big.NewInt(int64(v))
+	}
+	return res
+}
@@ -0,0 +1,120 @@
+package utils
+
+import (
+	"math"
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCheckedMul(t *testing.T) {
+	tests := []struct {
+		a, b, want int
+		ok         bool
+	}{
+		{3, 4, 12, true},
+		{-3, 4, -12, true},
+		{0, math.MinInt, 0, true},
+		{math.MaxInt, 2, 0, false},
+		{math.MinInt, -1, 0, false},
+		{-1, math.MinInt, 0, false},
+		{1 << 31, 1 << 31, 1 << 62, true},
+		{1 << 32, 1 << 31, 0, false},
+	}
+	for _, tc := range tests {
+		got, ok := checked_mul(tc.a, tc.b)
+		assert.Equal(t, tc.ok, ok, "%d * %d", tc.a, tc.b)
+		assert.Equal(t, tc.want, got, "%d * %d", tc.a, tc.b)
+	}
+
+	_, ok := checked_sub(math.MinInt, 1)
+	assert.False(t, ok)
+	_, ok = checked_sub(math.MaxInt, -1)
+	assert.False(t, ok)
+	got, ok := checked_sub(-1, math.MaxInt)
+	assert.True(t, ok)
+	assert.Equal(t, math.MinInt, got)
+}
+
+func TestSolveInt(t *testing.T) {
+	tests := []struct {
+		name string
+		a    [][]int
+		b    []int
+		want []int
+		err  error
+	}{
+		{"2x2", [][]int{{94, 22}, {34, 67}}, []int{8400, 5400}, []int{80, 40}, nil},
+		{"not integer", [][]int{{26, 67}, {66, 21}}, []int{12748, 12176}, nil, ErrNoIntegerSolution},
+		{"singular", [][]int{{1, 2}, {2, 4}}, []int{3, 6}, nil, ErrSingular},
+		{"needs a swap", [][]int{{0, 1}, {1, 0}}, []int{5, -7}, []int{-7, 5}, nil},
+		{
+			"3x3",
+			[][]int{{2, 1, -1}, {-3, -1, 2}, {-2, 1, 2}},
+			[]int{8, -11, -3},
+			[]int{2, 3, -1},
+			nil,
+		},
+		{"1x1", [][]int{{4}}, []int{-12}, []int{-3}, nil},
+		{"empty", [][]int{}, []int{}, []int{}, nil},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			a := [][]int{}
+			for _, row := range tc.a {
+				a = append(a, append([]int{}, row...))
+			}
+			got, err := SolveInt(tc.a, tc.b)
+			assert.Equal(t, tc.want, got)
+			assert.ErrorIs(t, err, tc.err)
+
+			// The input is not changed
+			assert.Equal(t, a, tc.a)
+		})
+	}
+}
+
+func TestSolveIntFallsBackToBig(t *testing.T) {
+	// The cross products are around 1e19, which overflows an int64, even though the
+	// answer fits
+	na, nb := int(1e15)+7, 3*int(1e15)+1
+	a := [][]int{{94, 22}, {34, 67}}
+	b := []int{94*na + 22*nb, 34*na + 67*nb}
+	_, ok, _ := solve_int(a, b)
+	assert.False(t, ok)
+
+	got, err := SolveInt(a, b)
+	assert.NoError(t, err)
+	assert.Equal(t, []int{na, nb}, got)
+
+	// The answer is right at the limit
+	got, err = SolveInt([][]int{{1, 1}, {1, -1}}, []int{math.MaxInt, math.MinInt + 1})
+	assert.NoError(t, err)
+	assert.Equal(t, []int{0, math.MaxInt}, got)
+
+	// The answer itself is too big
+	_, err = SolveInt([][]int{{1, -1}, {0, 1}}, []int{math.MaxInt, 1})
+	assert.ErrorIs(t, err, ErrSolutionOverflow)
+}
+
+func TestSolveIntBadShape(t *testing.T) {
+	_, err := SolveInt([][]int{{1, 2}}, []int{1})
+	assert.Error(t, err)
+	_, err = SolveInt([][]int{{1, 2}, {3, 4}}, []int{1})
+	assert.Error(t, err)
+}
+
+func TestSolveBig(t *testing.T) {
+	huge, _ := new(big.Int).SetString("123456789012345678901234567890", 10)
+	// x + y = huge + 1, x - y = huge - 1
+	a := to_big_matrix([][]int{{1, 1}, {1, -1}})
+	b := []*big.Int{new(big.Int).Add(huge, big.NewInt(1)), new(big.Int).Sub(huge, big.NewInt(1))}
+	got, err := SolveBig(a, b)
+	assert.NoError(t, err)
+	assert.Equal(t, 0, huge.Cmp(got[0]))
+	assert.Equal(t, int64(1), got[1].Int64())
+
+	_, err = SolveBig(to_big_matrix([][]int{{2, 0}, {0, 2}}), to_big_slice([]int{2, 3}))
+	assert.ErrorIs(t, err, ErrNoIntegerSolution)
+}
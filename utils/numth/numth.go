@@ -0,0 +1,188 @@
+// Package numth has number theory helpers for working with integers: greatest common
+// divisors, modular arithmetic, and the Chinese remainder theorem.
+package numth
+
+import (
+	"errors"
+	"math/bits"
+)
+
+// ErrOverflow is returned when an answer is too big for an int
+var ErrOverflow = errors.New("result does not fit in an int")
+
+// ErrNoInverse is returned by ModInverse when a and m share a factor
+var ErrNoInverse = errors.New("no modular inverse")
+
+// ErrNoSolution is returned by CRT when the congruences contradict each other
+var ErrNoSolution = errors.New("congruences have no common solution")
+
+// abs returns the absolute value of x
+func abs(x int) int {
+	if x < 0 {
+		return -x
+	}
+	return x
+}
+
+// mod returns a mod m in the range [0, m). m must be positive.
+func mod(a, m int) int {
+	r := a % m
+	if r < 0 {
+		r += m
+	}
+	return r
+}
+
+// GCD returns the greatest common divisor of all the numbers, which is never negative.
+// The GCD of no numbers, or only zeros, is 0.
+func GCD(nums ...int) int {
+	g := 0
+	for _, n := range nums {
+		a, b := g, abs(n)
+		for b != 0 {
+			a, b = b, a%b
+		}
+		g = a
+		if g == 1 {
+			break
+		}
+	}
+	return g
+}
+
+// LCM returns the least common multiple of all the numbers, which is never negative. If
+// any number is 0, the LCM is 0. The LCM of no numbers is 1. It returns ErrOverflow if
+// the answer is too big for an int.
+func LCM(nums ...int) (int, error) {
+	l := 1
+	for _, n := range nums {
+		n = abs(n)
+		if n == 0 {
+			return 0, nil
+		}
+		hi, lo := bits.Mul64(uint64(l/GCD(l, n)), uint64(n))
+		if hi != 0 || lo > uint64(1<<63-1) {
+			return 0, ErrOverflow
+		}
+		l = int(lo)
+	}
+	return l, nil
+}
+
+// ExtGCD returns g = gcd(a, b), along with x and y such that a*x + b*y = g. g is never
+// negative.
+func ExtGCD(a, b int) (g, x, y int) {
+	old_r, r := a, b
+	old_x, x := 1, 0
+	old_y, y := 0, 1
+	for r != 0 {
+		q := old_r / r
+		old_r, r = r, old_r-q*r
+		old_x, x = x, old_x-q*x
+		old_y, y = y, old_y-q*y
+	}
+	if old_r < 0 {
+		return -old_r, -old_x, -old_y
+	}
+	return old_r, old_x, old_y
+}
+
+// ModInverse returns x in [0, m) such that a*x ≡ 1 (mod m). It returns ErrNoInverse if
+// a and m are not coprime. m must be positive.
+func ModInverse(a, m int) (int, error) {
+	g, x, _ := ExtGCD(mod(a, m), m)
+	if g != 1 {
+		return 0, ErrNoInverse
+	}
+	return mod(x, m), nil
+}
+
+// MulMod returns a*b mod m in the range [0, m), without overflowing even when a*b is
+// bigger than an int. m must be positive.
+func MulMod(a, b, m int) int {
+	hi, lo := bits.Mul64(uint64(mod(a, m)), uint64(mod(b, m)))
+	// hi < m because both factors are less than m, so Rem64 won't panic
+	return int(bits.Rem64(hi, lo, uint64(m)))
+}
+
+// AddMod returns (a + b) mod m in the range [0, m), without overflowing even when m is
+// close to math.MaxInt. m must be positive.
+func AddMod(a, b, m int) int {
+	a, b = mod(a, m), mod(b, m)
+	// a + b >= m, worked out without adding them
+	if a >= m-b {
+		return a - (m - b)
+	}
+	return a + b
+}
+
+// ModPow returns base^exp mod m in the range [0, m), by repeated squaring. exp must not
+// be negative, and m must be positive.
+func ModPow(base, exp, m int) int {
+	if exp < 0 {
+		panic("ModPow: negative exponent")
+	}
+	res := 1 % m
+	base = mod(base, m)
+	for exp > 0 {
+		if exp&1 == 1 {
+			res = MulMod(res, base, m)
+		}
+		base = MulMod(base, base, m)
+		exp >>= 1
+	}
+	return res
+}
+
+// CRT finds x such that x ≡ residues[i] (mod moduli[i]) for every i. The moduli do not
+// need to be coprime. It returns x in the range [0, m), where m is the LCM of the
+// moduli, so every answer is x + k*m. It returns ErrNoSolution if the congruences
+// contradict each other, and ErrOverflow if m is too big for an int. The moduli must all
+// be positive.
+func CRT(residues, moduli []int) (x, m int, err error) {
+	if len(residues) != len(moduli) {
+		panic("CRT: residues and moduli must be the same length")
+	}
+
+	x, m = 0, 1
+	for idx, mi := range moduli {
+		ri := mod(residues[idx], mi)
+
+		// Solve x + m*t ≡ ri (mod mi), which needs g to divide the difference
+		g, p, _ := ExtGCD(m, mi)
+		diff := ri - mod(x, mi)
+		if diff%g != 0 {
+			return 0, 0, ErrNoSolution
+		}
+
+		// p is the inverse of m/g modulo mi/g
+		step := mi / g
+		t := MulMod(diff/g, p, step)
+
+		lcm, err := LCM(m, mi)
+		if err != nil {
+			return 0, 0, err
+		}
+		x = AddMod(x, MulMod(m, t, lcm), lcm)
+		m = lcm
+	}
+	return x, m, nil
+}
+
+// FloorDiv divides a by b, rounding towards negative infinity rather than towards 0
+func FloorDiv(a, b int) int {
+	q := a / b
+	if a%b != 0 && (a < 0) != (b < 0) {
+		q--
+	}
+	return q
+}
+
+// CeilDiv divides a by b, rounding towards positive infinity
+func CeilDiv(a, b int) int {
+	q := a / b
+	if a%b != 0 && (a < 0) == (b < 0) {
+		q++
+	}
+	return q
+}
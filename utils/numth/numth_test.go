@@ -0,0 +1,182 @@
+package numth
+
+import (
+	"math"
+	"math/big"
+	"math/rand/v2"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGCD(t *testing.T) {
+	tests := []struct {
+		nums []int
+		want int
+	}{
+		{[]int{12, 18}, 6},
+		{[]int{-12, 18}, 6},
+		{[]int{12, -18, 27}, 3},
+		{[]int{7, 13}, 1},
+		{[]int{0, 5}, 5},
+		{[]int{0, 0}, 0},
+		{[]int{}, 0},
+		{[]int{-9}, 9},
+	}
+	for _, tc := range tests {
+		assert.Equal(t, tc.want, GCD(tc.nums...), "%v", tc.nums)
+	}
+}
+
+func TestLCM(t *testing.T) {
+	tests := []struct {
+		nums []int
+		want int
+		err  error
+	}{
+		{[]int{4, 6}, 12, nil},
+		{[]int{101, 103}, 10403, nil},
+		{[]int{2, 3, 4, 5, 6}, 60, nil},
+		{[]int{-4, 6}, 12, nil},
+		{[]int{4, 0}, 0, nil},
+		{[]int{}, 1, nil},
+		{[]int{1 << 40, 3 << 30}, 3 << 40, nil},
+		{[]int{1<<40 + 1, 1<<40 - 1}, 0, ErrOverflow},
+	}
+	for _, tc := range tests {
+		got, err := LCM(tc.nums...)
+		assert.Equal(t, tc.want, got, "%v", tc.nums)
+		assert.ErrorIs(t, err, tc.err)
+	}
+}
+
+func TestExtGCD(t *testing.T) {
+	for _, pair := range [][2]int{{240, 46}, {46, 240}, {-240, 46}, {240, -46}, {0, 7}, {7, 0}, {0, 0}, {1, 1}, {17, 5}} {
+		a, b := pair[0], pair[1]
+		g, x, y := ExtGCD(a, b)
+		assert.Equal(t, GCD(a, b), g, "%d, %d", a, b)
+		assert.Equal(t, g, a*x+b*y, "%d, %d", a, b)
+	}
+}
+
+func TestModInverse(t *testing.T) {
+	got, err := ModInverse(3, 11)
+	assert.NoError(t, err)
+	assert.Equal(t, 4, got)
+
+	got, err = ModInverse(-3, 11)
+	assert.NoError(t, err)
+	assert.Equal(t, 7, got)
+
+	got, err = ModInverse(101, 103)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, (101*got)%103)
+
+	_, err = ModInverse(6, 9)
+	assert.ErrorIs(t, err, ErrNoInverse)
+}
+
+func TestMulMod(t *testing.T) {
+	r := rand.New(rand.NewPCG(1, 2))
+	for range 1000 {
+		a, b := r.IntN(math.MaxInt)-r.IntN(math.MaxInt), r.IntN(math.MaxInt)
+		m := r.IntN(math.MaxInt) + 1
+
+		// Check against math/big
+		want := new(big.Int).Mul(big.NewInt(int64(a)), big.NewInt(int64(b)))
+		want.Mod(want, big.NewInt(int64(m)))
+		assert.Equal(t, want.Int64(), int64(MulMod(a, b, m)), "%d * %d mod %d", a, b, m)
+	}
+
+	assert.Equal(t, 0, MulMod(5, 7, 1))
+	assert.Equal(t, 1, MulMod(math.MaxInt, math.MaxInt, math.MaxInt-1))
+}
+
+func TestModPow(t *testing.T) {
+	assert.Equal(t, 445, ModPow(4, 13, 497))
+	assert.Equal(t, 1, ModPow(123, 0, 7))
+	assert.Equal(t, 0, ModPow(123, 0, 1))
+	assert.Equal(t, 6, ModPow(-1, 3, 7))
+
+	// Fermat's little theorem with a big prime
+	p := 1<<61 - 1
+	assert.Equal(t, 1, ModPow(123456789, p-1, p))
+
+	// Powers of 2 modulo 2^24, like day 22's secret numbers
+	assert.Equal(t, 1<<23, ModPow(2, 23, 1<<24))
+	assert.Equal(t, 0, ModPow(2, 24, 1<<24))
+}
+
+func TestAddMod(t *testing.T) {
+	tests := []struct {
+		a, b, m, want int
+	}{
+		{3, 4, 5, 2},
+		{-3, 1, 5, 3},
+		{0, 0, 1, 0},
+		{math.MaxInt - 1, math.MaxInt - 1, math.MaxInt, math.MaxInt - 2},
+		{1<<62 + 5, 1<<62 + 7, 1<<62 + 9, 1<<62 + 3},
+		{math.MinInt, math.MinInt, math.MaxInt, math.MaxInt - 2},
+	}
+	for _, tc := range tests {
+		assert.Equal(t, tc.want, AddMod(tc.a, tc.b, tc.m), "%d + %d mod %d", tc.a, tc.b, tc.m)
+	}
+}
+
+func TestCRT(t *testing.T) {
+	tests := []struct {
+		name     string
+		residues []int
+		moduli   []int
+		x, m     int
+		err      error
+	}{
+		{"coprime", []int{2, 3, 2}, []int{3, 5, 7}, 23, 105, nil},
+		{"torus", []int{1, 2}, []int{101, 103}, 5152, 10403, nil},
+		{"negative residue", []int{-1, 0}, []int{4, 3}, 3, 12, nil},
+		{"not coprime", []int{2, 4}, []int{6, 8}, 20, 24, nil},
+		{"not coprime, contradiction", []int{1, 2}, []int{6, 8}, 0, 0, ErrNoSolution},
+		{"same modulus twice", []int{3, 3}, []int{5, 5}, 3, 5, nil},
+		{"empty", []int{}, []int{}, 0, 1, nil},
+		{"too big", []int{0, 0}, []int{1<<40 + 1, 1<<40 - 1}, 0, 0, ErrOverflow},
+		{"big but fits", []int{1, 2}, []int{1<<31 - 1, 1<<31 + 11}, 0, (1<<31 - 1) * (1<<31 + 11), nil},
+		{"near 2^62", []int{-1, 0}, []int{1<<62 - 57, 2}, 1<<62 - 58, 2 * (1<<62 - 57), nil},
+		{"near 2^62, answer near the LCM", []int{-1, 1}, []int{1<<62 - 57, 2}, 2*(1<<62-57) - 1, 2 * (1<<62 - 57), nil},
+		{"LCM is MaxInt", []int{-1, 6}, []int{math.MaxInt, 7}, math.MaxInt - 1, math.MaxInt, nil},
+		{"near 2^62, too big", []int{1, 2}, []int{1<<62 - 57, 1<<62 - 87}, 0, 0, ErrOverflow},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			x, m, err := CRT(tc.residues, tc.moduli)
+			assert.ErrorIs(t, err, tc.err)
+			if tc.err != nil {
+				return
+			}
+			assert.Equal(t, tc.m, m)
+			if tc.x != 0 {
+				assert.Equal(t, tc.x, x)
+			}
+			for idx, mi := range tc.moduli {
+				assert.Equal(t, mod(tc.residues[idx], mi), x%mi)
+			}
+		})
+	}
+}
+
+func TestFloorCeilDiv(t *testing.T) {
+	tests := []struct {
+		a, b, floor, ceil int
+	}{
+		{7, 2, 3, 4},
+		{-7, 2, -4, -3},
+		{7, -2, -4, -3},
+		{-7, -2, 3, 4},
+		{6, 2, 3, 3},
+		{-6, 2, -3, -3},
+		{0, 5, 0, 0},
+	}
+	for _, tc := range tests {
+		assert.Equal(t, tc.floor, FloorDiv(tc.a, tc.b), "floor %d / %d", tc.a, tc.b)
+		assert.Equal(t, tc.ceil, CeilDiv(tc.a, tc.b), "ceil %d / %d", tc.a, tc.b)
+	}
+}
@@ -0,0 +1,98 @@
+package utils
+
+import (
+	"runtime"
+	"sync"
+	"sync/atomic"
+)
+
+// workers_for returns how many goroutines to use for `n` items. Asking for 0 or fewer
+// workers means one per CPU, and there are never more workers than items.
+func workers_for(workers, n int) int {
+	if workers <= 0 {
+		workers = runtime.GOMAXPROCS(0)
+	}
+	return max(1, min(workers, n))
+}
+
+// ParallelMap calls `f` on every item using `workers` goroutines, and returns the results
+// in the same order as `items`. If `workers` is 0 or less, it uses one per CPU. `f` must
+// be safe to call from several goroutines at once.
+func ParallelMap[T, R any](items []T, workers int, f func(T) R) []R {
+	res := make([]R, len(items))
+	workers = workers_for(workers, len(items))
+	if workers == 1 {
+		for i, item := range items {
+			res[i] = f(item)
+		}
+		return res
+	}
+
+	// Each worker takes the next item that nobody has claimed yet, so slow items don't
+	// hold up the rest. Every result has its own slot, so the order never changes.
+	var next atomic.Int64
+	var wg sync.WaitGroup
+	wg.Add(workers)
+	for range workers {
+		go func() {
+			defer wg.Done()
+			for {
+				i := int(next.Add(1) - 1)
+				if i >= len(items) {
+					return
+				}
+				res[i] = f(items[i])
+			}
+		}()
+	}
+	wg.Wait()
+	return res
+}
+
+// ParallelReduce splits `items` into `workers` runs of neighbouring items, and folds each
+// run into a value that starts off as `init()`. The values for each run are then merged
+// from left to right. If `workers` is 0 or less, it uses one per CPU.
+//
+// For a given number of workers the result is always the same. For the result to not
+// depend on the number of workers, `merge` must be associative and `init()` must be an
+// identity for it. Each run gets its own value from `init`, so `fold` can change its
+// value in place, and keep scratch space in it.
+func ParallelReduce[T, A any](items []T, workers int, init func() A, fold func(A, T) A, merge func(A, A) A) A {
+	workers = workers_for(workers, len(items))
+	if workers == 1 {
+		acc := init()
+		for _, item := range items {
+			acc = fold(acc, item)
+		}
+		return acc
+	}
+
+	// Split as evenly as possible, with the first runs one longer if it doesn't divide
+	runs := make([]A, workers)
+	var wg sync.WaitGroup
+	wg.Add(workers)
+	start := 0
+	for w := range workers {
+		end := start + len(items)/workers
+		if w < len(items)%workers {
+			end++
+		}
+		run := items[start:end]
+		go func() {
+			defer wg.Done()
+			acc := init()
+			for _, item := range run {
+				acc = fold(acc, item)
+			}
+			runs[w] = acc
+		}()
+		start = end
+	}
+	wg.Wait()
+
+	acc := runs[0]
+	for _, r := range runs[1:] {
+		acc = merge(acc, r)
+	}
+	return acc
+}
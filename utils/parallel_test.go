@@ -0,0 +1,67 @@
+package utils
+
+import (
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParallelMap(t *testing.T) {
+	items := make([]int, 1000)
+	for i := range items {
+		items[i] = i
+	}
+	want := make([]string, len(items))
+	for i := range items {
+		want[i] = strconv.Itoa(i * i)
+	}
+
+	for _, workers := range []int{-1, 0, 1, 3, 8, 5000} {
+		t.Run(strconv.Itoa(workers), func(t *testing.T) {
+			var calls atomic.Int64
+			got := ParallelMap(items, workers, func(x int) string {
+				calls.Add(1)
+				return strconv.Itoa(x * x)
+			})
+			assert.Equal(t, want, got)
+			assert.Equal(t, int64(len(items)), calls.Load())
+		})
+	}
+
+	assert.Empty(t, ParallelMap([]int{}, 4, strconv.Itoa))
+}
+
+func TestParallelReduce(t *testing.T) {
+	items := make([]int, 1001)
+	for i := range items {
+		items[i] = i
+	}
+
+	// Summing doesn't depend on how the items are split up
+	for _, workers := range []int{0, 1, 2, 7, 2000} {
+		got := ParallelReduce(items, workers,
+			func() int { return 0 },
+			func(acc, x int) int { return acc + x },
+			func(a, b int) int { return a + b },
+		)
+		assert.Equal(t, 1000*1001/2, got)
+	}
+
+	// Joining strings isn't commutative, so this checks the runs are merged in order
+	words := strings.Fields("the quick brown fox jumps over the lazy dog")
+	for _, workers := range []int{1, 2, 4, 9} {
+		got := ParallelReduce(words, workers,
+			func() []string { return nil },
+			func(acc []string, w string) []string { return append(acc, w) },
+			func(a, b []string) []string { return append(a, b...) },
+		)
+		assert.Equal(t, words, got)
+	}
+
+	// No items gives the starting value
+	got := ParallelReduce([]int{}, 4, func() int { return 42 }, func(a, x int) int { return a + x }, func(a, b int) int { return a + b })
+	assert.Equal(t, 42, got)
+}
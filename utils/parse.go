@@ -0,0 +1,189 @@
+package utils
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// ParseError says which line and which struct field could not be parsed
+type ParseError struct {
+	// The line number, starting from 1
+	Line int
+
+	// The name of the struct field, or empty if the line did not match at all
+	Field string
+
+	Err error
+}
+
+func (e *ParseError) Error() string {
+	if e.Field == "" {
+		return fmt.Sprintf("line %d: %v", e.Line, e.Err)
+	}
+	return fmt.Sprintf("line %d: field %s: %v", e.Line, e.Field, e.Err)
+}
+
+func (e *ParseError) Unwrap() error {
+	return e.Err
+}
+
+// ErrNoMatch is returned (wrapped in a ParseError) when a line does not match the regex
+var ErrNoMatch = errors.New("does not match the regex")
+
+// field_plan says which capture group fills which struct field
+type field_plan struct {
+	name      string
+	field_idx int
+	group_idx int
+}
+
+// plan_fields works out which capture group goes in each field of T. A field is filled
+// from the group named in its `re` tag, e.g. `re:"x"`, or if it has no tag, the group
+// with the same name as the field. A tag of `re:"-"` skips the field. Unexported fields
+// are always skipped.
+func plan_fields(t reflect.Type, re *regexp.Regexp) ([]field_plan, error) {
+	if t == nil || t.Kind() != reflect.Struct {
+		return nil, fmt.Errorf("can only parse into a struct, not %v", t)
+	}
+
+	plan := make([]field_plan, 0, t.NumField())
+	for idx := range t.NumField() {
+		f := t.Field(idx)
+		if !f.IsExported() {
+			continue
+		}
+
+		tag, has_tag := f.Tag.Lookup("re")
+		if tag == "-" {
+			continue
+		}
+		group := f.Name
+		if has_tag {
+			group = tag
+		}
+
+		group_idx := re.SubexpIndex(group)
+		if group_idx == -1 {
+			// Only complain if the tag asked for a group that isn't there
+			if has_tag {
+				return nil, fmt.Errorf("field %s: no capture group named %q", f.Name, group)
+			}
+			continue
+		}
+
+		switch f.Type.Kind() {
+		case reflect.String, reflect.Bool, reflect.Float32, reflect.Float64,
+			reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+			reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		default:
+			return nil, fmt.Errorf("field %s: can't parse into type %v", f.Name, f.Type)
+		}
+		plan = append(plan, field_plan{f.Name, idx, group_idx})
+	}
+	return plan, nil
+}
+
+// set_field converts the text to the type of the field, and stores it
+func set_field(field reflect.Value, text string) error {
+	switch field.Kind() {
+	case reflect.String:
+		field.SetString(text)
+	case reflect.Bool:
+		// Accepts 1/0 and true/false like ParseBool, along with t/f
+		b, err := strconv.ParseBool(strings.TrimSpace(text))
+		if err != nil {
+			return err
+		}
+		field.SetBool(b)
+	case reflect.Float32, reflect.Float64:
+		f, err := strconv.ParseFloat(text, field.Type().Bits())
+		if err != nil {
+			return err
+		}
+		field.SetFloat(f)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		n, err := strconv.ParseInt(text, 10, field.Type().Bits())
+		if err != nil {
+			return err
+		}
+		field.SetInt(n)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		n, err := strconv.ParseUint(text, 10, field.Type().Bits())
+		if err != nil {
+			return err
+		}
+		field.SetUint(n)
+	}
+	return nil
+}
+
+// fill_struct fills in `v` from a single line. Any optional group that did not take
+// part in the match leaves its field at the zero value.
+func fill_struct(v reflect.Value, plan []field_plan, re *regexp.Regexp, line string, line_num int) error {
+	loc := re.FindStringSubmatchIndex(line)
+	if loc == nil {
+		return &ParseError{Line: line_num, Err: ErrNoMatch}
+	}
+
+	for _, fp := range plan {
+		start, end := loc[2*fp.group_idx], loc[2*fp.group_idx+1]
+		if start == -1 {
+			continue
+		}
+		if err := set_field(v.Field(fp.field_idx), line[start:end]); err != nil {
+			return &ParseError{Line: line_num, Field: fp.name, Err: err}
+		}
+	}
+	return nil
+}
+
+// ParseLine fills in the exported fields of a T struct from the named capture groups of
+// `re` matched against `line`. E.g. with
+//
+//	type Button struct {
+//		X int `re:"dx"`
+//		Y int `re:"dy"`
+//	}
+//
+// and the regex `X\+(?P<dx>\d+), Y\+(?P<dy>\d+)`, "Button A: X+94, Y+34" becomes
+// Button{94, 34}. Fields without a tag are filled from the group with the same name as
+// the field. Fields can be strings, bools, ints, uints, or floats.
+func ParseLine[T any](re *regexp.Regexp, line string) (T, error) {
+	var res T
+	plan, err := plan_fields(reflect.TypeOf(res), re)
+	if err != nil {
+		return res, err
+	}
+	err = fill_struct(reflect.ValueOf(&res).Elem(), plan, re, line, 1)
+	return res, err
+}
+
+// ParseInto is ParseLine for each line of the text, skipping blank lines. Every other
+// line must match `re`. Any error says which line (starting from 1) and which field
+// failed.
+func ParseInto[T any](re *regexp.Regexp, text string) ([]T, error) {
+	var zero T
+	plan, err := plan_fields(reflect.TypeOf(zero), re)
+	if err != nil {
+		return nil, err
+	}
+
+	lines := strings.Split(text, "\n")
+	res := make([]T, 0, len(lines))
+	for idx, line := range lines {
+		line = strings.TrimRight(line, "\r")
+		if strings.TrimSpace(line) == "" {
+			continue
+		}
+		var item T
+		if err := fill_struct(reflect.ValueOf(&item).Elem(), plan, re, line, idx+1); err != nil {
+			return nil, err
+		}
+		res = append(res, item)
+	}
+	return res, nil
+}
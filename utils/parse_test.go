@@ -0,0 +1,108 @@
+package utils
+
+import (
+	"errors"
+	"regexp"
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type test_record struct {
+	Name    string  `re:"name"`
+	Age     int     `re:"age"`
+	Height  float64 `re:"height"`
+	Member  bool    `re:"member"`
+	Visits  uint8   `re:"visits"`
+	Ignored string  `re:"-"`
+	hidden  int
+}
+
+var record_re = regexp.MustCompile(
+	`^(?P<name>\w+) age=(?P<age>-?\d+) height=(?P<height>[\d.]+) member=(?P<member>\w+)(?: visits=(?P<visits>\d+))?$`,
+)
+
+func TestParseLine(t *testing.T) {
+	got, err := ParseLine[test_record](record_re, "ann age=34 height=1.72 member=true visits=12")
+	assert.NoError(t, err)
+	assert.Equal(t, test_record{Name: "ann", Age: 34, Height: 1.72, Member: true, Visits: 12}, got)
+
+	// The optional group is left as the zero value
+	got, err = ParseLine[test_record](record_re, "bob age=-1 height=2 member=0")
+	assert.NoError(t, err)
+	assert.Equal(t, test_record{Name: "bob", Age: -1, Height: 2, Member: false}, got)
+}
+
+func TestParseLineFieldNames(t *testing.T) {
+	// With no tags, the group names match the field names
+	re := regexp.MustCompile(`p=(?P<X>-?\d+),(?P<Y>-?\d+)`)
+	got, err := ParseLine[Vec2](re, "p=-3,4")
+	assert.NoError(t, err)
+	assert.Equal(t, Vec2{-3, 4}, got)
+}
+
+func TestParseInto(t *testing.T) {
+	text := "ann age=34 height=1.72 member=true\r\n\nbob age=5 height=1.1 member=false visits=3\n"
+	got, err := ParseInto[test_record](record_re, text)
+	assert.NoError(t, err)
+	assert.Equal(t, []test_record{
+		{Name: "ann", Age: 34, Height: 1.72, Member: true},
+		{Name: "bob", Age: 5, Height: 1.1, Visits: 3},
+	}, got)
+}
+
+func TestParseIntoErrors(t *testing.T) {
+	tests := []struct {
+		name     string
+		text     string
+		line     int
+		field    string
+		err_is   error
+		err_text string
+	}{
+		{
+			"no match", "ann age=34 height=1.72 member=true\nnope",
+			2, "", ErrNoMatch, "line 2: does not match the regex",
+		},
+		{
+			"bad bool", "ann age=34 height=1.72 member=yes",
+			1, "Member", strconv.ErrSyntax, `line 1: field Member: strconv.ParseBool: parsing "yes": invalid syntax`,
+		},
+		{
+			"overflow", "ann age=34 height=1.72 member=true\n\nann age=3 height=1 member=true visits=300",
+			3, "Visits", strconv.ErrRange, `line 3: field Visits: strconv.ParseUint: parsing "300": value out of range`,
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := ParseInto[test_record](record_re, tc.text)
+			var pe *ParseError
+			assert.True(t, errors.As(err, &pe))
+			assert.Equal(t, tc.line, pe.Line)
+			assert.Equal(t, tc.field, pe.Field)
+			assert.ErrorIs(t, err, tc.err_is)
+			assert.EqualError(t, err, tc.err_text)
+		})
+	}
+}
+
+func TestParseIntoBadTypes(t *testing.T) {
+	// A tag that names a group that isn't in the regex
+	type missing struct {
+		A int `re:"nope"`
+	}
+	_, err := ParseInto[missing](regexp.MustCompile(`(?P<a>\d)`), "1")
+	assert.EqualError(t, err, `field A: no capture group named "nope"`)
+
+	// A field type that can't be parsed into
+	type bad_type struct {
+		A []int `re:"a"`
+	}
+	_, err = ParseLine[bad_type](regexp.MustCompile(`(?P<a>\d)`), "1")
+	assert.EqualError(t, err, "field A: can't parse into type []int")
+
+	// Not a struct
+	_, err = ParseLine[int](regexp.MustCompile(`(?P<a>\d)`), "1")
+	assert.Error(t, err)
+}
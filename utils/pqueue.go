@@ -0,0 +1,224 @@
+package utils
+
+// PriorityQueue is a binary heap where the item that is `less` than all the others
+// comes out first. Unlike container/heap, there is no interface to implement; just give
+// NewPriorityQueue a less function.
+type PriorityQueue[T any] struct {
+	items []T
+	less  func(a, b T) bool
+}
+
+// NewPriorityQueue creates an empty queue. Use `func(a, b T) bool { return a < b }` for
+// a min-queue, and `a > b` for a max-queue.
+func NewPriorityQueue[T any](less func(a, b T) bool) *PriorityQueue[T] {
+	return &PriorityQueue[T]{less: less}
+}
+
+// Len returns the number of items in the queue
+func (pq *PriorityQueue[T]) Len() int {
+	return len(pq.items)
+}
+
+// Push adds an item to the queue
+func (pq *PriorityQueue[T]) Push(item T) {
+	pq.items = append(pq.items, item)
+	pq.up(len(pq.items) - 1)
+}
+
+// Peek returns the first item without removing it. It panics if the queue is empty.
+func (pq *PriorityQueue[T]) Peek() T {
+	return pq.items[0]
+}
+
+// Pop removes and returns the first item. It panics if the queue is empty.
+func (pq *PriorityQueue[T]) Pop() T {
+	top := pq.items[0]
+	last := len(pq.items) - 1
+	pq.items[0] = pq.items[last]
+
+	// Zero out the old last item, so it can be garbage collected
+	var zero T
+	pq.items[last] = zero
+	pq.items = pq.items[:last]
+
+	if last > 0 {
+		pq.down(0)
+	}
+	return top
+}
+
+// up moves the item at idx towards the top until its parent is not greater than it
+func (pq *PriorityQueue[T]) up(idx int) {
+	for idx > 0 {
+		parent := (idx - 1) / 2
+		if !pq.less(pq.items[idx], pq.items[parent]) {
+			break
+		}
+		pq.items[idx], pq.items[parent] = pq.items[parent], pq.items[idx]
+		idx = parent
+	}
+}
+
+// down moves the item at idx towards the bottom until neither child is less than it
+func (pq *PriorityQueue[T]) down(idx int) {
+	for {
+		smallest := idx
+		if left := 2*idx + 1; left < len(pq.items) && pq.less(pq.items[left], pq.items[smallest]) {
+			smallest = left
+		}
+		if right := 2*idx + 2; right < len(pq.items) && pq.less(pq.items[right], pq.items[smallest]) {
+			smallest = right
+		}
+		if smallest == idx {
+			return
+		}
+		pq.items[idx], pq.items[smallest] = pq.items[smallest], pq.items[idx]
+		idx = smallest
+	}
+}
+
+// indexed_item is a key and its priority, as stored in an IndexedPriorityQueue
+type indexed_item[K comparable, P any] struct {
+	key      K
+	priority P
+}
+
+// IndexedPriorityQueue is a priority queue where each key can only be in the queue once,
+// and its priority can be changed while it is in the queue (e.g. the "decrease-key" step
+// of Dijkstra's algorithm).
+type IndexedPriorityQueue[K comparable, P any] struct {
+	items []indexed_item[K, P]
+
+	// Where each key is in `items`
+	index map[K]int
+
+	less func(a, b P) bool
+}
+
+// NewIndexedPriorityQueue creates an empty queue, where the key with the priority that
+// is `less` than all the others comes out first.
+func NewIndexedPriorityQueue[K comparable, P any](less func(a, b P) bool) *IndexedPriorityQueue[K, P] {
+	return &IndexedPriorityQueue[K, P]{
+		index: make(map[K]int),
+		less:  less,
+	}
+}
+
+// Len returns the number of keys in the queue
+func (pq *IndexedPriorityQueue[K, P]) Len() int {
+	return len(pq.items)
+}
+
+// Contains checks if the key is in the queue
+func (pq *IndexedPriorityQueue[K, P]) Contains(key K) bool {
+	_, ok := pq.index[key]
+	return ok
+}
+
+// Priority returns the priority of a key, and false if it isn't in the queue
+func (pq *IndexedPriorityQueue[K, P]) Priority(key K) (P, bool) {
+	idx, ok := pq.index[key]
+	if !ok {
+		var zero P
+		return zero, false
+	}
+	return pq.items[idx].priority, true
+}
+
+// Set adds the key to the queue, or changes its priority if it is already in the queue.
+func (pq *IndexedPriorityQueue[K, P]) Set(key K, priority P) {
+	idx, ok := pq.index[key]
+	if !ok {
+		pq.items = append(pq.items, indexed_item[K, P]{key, priority})
+		idx = len(pq.items) - 1
+		pq.index[key] = idx
+		pq.up(idx)
+		return
+	}
+
+	old := pq.items[idx].priority
+	pq.items[idx].priority = priority
+	if pq.less(priority, old) {
+		pq.up(idx)
+	} else {
+		pq.down(idx)
+	}
+}
+
+// Peek returns the first key and its priority without removing it. It panics if the
+// queue is empty.
+func (pq *IndexedPriorityQueue[K, P]) Peek() (K, P) {
+	return pq.items[0].key, pq.items[0].priority
+}
+
+// Pop removes and returns the first key and its priority. It panics if the queue is
+// empty.
+func (pq *IndexedPriorityQueue[K, P]) Pop() (K, P) {
+	top := pq.items[0]
+	pq.remove_at(0)
+	return top.key, top.priority
+}
+
+// Remove takes a key out of the queue. It returns false if the key was not in the queue.
+func (pq *IndexedPriorityQueue[K, P]) Remove(key K) bool {
+	idx, ok := pq.index[key]
+	if !ok {
+		return false
+	}
+	pq.remove_at(idx)
+	return true
+}
+
+// remove_at swaps the item at idx with the last item, removes it, and then fixes up the
+// heap around the item that was moved
+func (pq *IndexedPriorityQueue[K, P]) remove_at(idx int) {
+	last := len(pq.items) - 1
+	delete(pq.index, pq.items[idx].key)
+	if idx != last {
+		pq.items[idx] = pq.items[last]
+		pq.index[pq.items[idx].key] = idx
+	}
+	pq.items[last] = indexed_item[K, P]{}
+	pq.items = pq.items[:last]
+
+	if idx < len(pq.items) {
+		pq.down(idx)
+		pq.up(idx)
+	}
+}
+
+func (pq *IndexedPriorityQueue[K, P]) swap(i, j int) {
+	pq.items[i], pq.items[j] = pq.items[j], pq.items[i]
+	pq.index[pq.items[i].key] = i
+	pq.index[pq.items[j].key] = j
+}
+
+// up moves the item at idx towards the top until its parent is not greater than it
+func (pq *IndexedPriorityQueue[K, P]) up(idx int) {
+	for idx > 0 {
+		parent := (idx - 1) / 2
+		if !pq.less(pq.items[idx].priority, pq.items[parent].priority) {
+			break
+		}
+		pq.swap(idx, parent)
+		idx = parent
+	}
+}
+
+// down moves the item at idx towards the bottom until neither child is less than it
+func (pq *IndexedPriorityQueue[K, P]) down(idx int) {
+	for {
+		smallest := idx
+		if left := 2*idx + 1; left < len(pq.items) && pq.less(pq.items[left].priority, pq.items[smallest].priority) {
+			smallest = left
+		}
+		if right := 2*idx + 2; right < len(pq.items) && pq.less(pq.items[right].priority, pq.items[smallest].priority) {
+			smallest = right
+		}
+		if smallest == idx {
+			return
+		}
+		pq.swap(idx, smallest)
+		idx = smallest
+	}
+}
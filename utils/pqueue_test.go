@@ -0,0 +1,129 @@
+package utils
+
+import (
+	"math/rand/v2"
+	"slices"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPriorityQueue(t *testing.T) {
+	pq := NewPriorityQueue(func(a, b int) bool { return a < b })
+	input := []int{5, 3, 9, 1, 1, 8, 2, 7}
+	for _, v := range input {
+		pq.Push(v)
+	}
+	assert.Equal(t, len(input), pq.Len())
+	assert.Equal(t, 1, pq.Peek())
+
+	got := make([]int, 0, len(input))
+	for pq.Len() > 0 {
+		got = append(got, pq.Pop())
+	}
+	assert.Equal(t, []int{1, 1, 2, 3, 5, 7, 8, 9}, got)
+	assert.Panics(t, func() { pq.Pop() })
+}
+
+func TestPriorityQueueMax(t *testing.T) {
+	type task struct {
+		name     string
+		priority int
+	}
+	pq := NewPriorityQueue(func(a, b task) bool { return a.priority > b.priority })
+	pq.Push(task{"low", 1})
+	pq.Push(task{"high", 10})
+	pq.Push(task{"mid", 5})
+
+	assert.Equal(t, "high", pq.Pop().name)
+	assert.Equal(t, "mid", pq.Pop().name)
+	assert.Equal(t, "low", pq.Pop().name)
+}
+
+func TestPriorityQueueRandom(t *testing.T) {
+	r := rand.New(rand.NewPCG(1, 2))
+	pq := NewPriorityQueue(func(a, b int) bool { return a < b })
+	want := make([]int, 0)
+
+	// Interleave pushes and pops, and check against a sorted slice
+	for range 1000 {
+		if r.IntN(3) == 0 && pq.Len() > 0 {
+			slices.Sort(want)
+			assert.Equal(t, want[0], pq.Pop())
+			want = want[1:]
+			continue
+		}
+		v := r.IntN(100)
+		pq.Push(v)
+		want = append(want, v)
+	}
+	assert.Equal(t, len(want), pq.Len())
+}
+
+func TestIndexedPriorityQueue(t *testing.T) {
+	pq := NewIndexedPriorityQueue[string](func(a, b int) bool { return a < b })
+	pq.Set("a", 5)
+	pq.Set("b", 3)
+	pq.Set("c", 8)
+	pq.Set("d", 1)
+	assert.Equal(t, 4, pq.Len())
+
+	// Decrease a key
+	pq.Set("c", 0)
+	assert.True(t, pq.Contains("c"))
+	p, ok := pq.Priority("c")
+	assert.True(t, ok)
+	assert.Equal(t, 0, p)
+
+	// Increase a key
+	pq.Set("d", 10)
+
+	key, priority := pq.Peek()
+	assert.Equal(t, "c", key)
+	assert.Equal(t, 0, priority)
+
+	// Remove one from the middle
+	assert.True(t, pq.Remove("a"))
+	assert.False(t, pq.Remove("a"))
+	assert.False(t, pq.Contains("a"))
+	_, ok = pq.Priority("a")
+	assert.False(t, ok)
+
+	got := make([]string, 0)
+	for pq.Len() > 0 {
+		k, _ := pq.Pop()
+		got = append(got, k)
+	}
+	assert.Equal(t, []string{"c", "b", "d"}, got)
+}
+
+func TestIndexedPriorityQueueRandom(t *testing.T) {
+	r := rand.New(rand.NewPCG(3, 4))
+	pq := NewIndexedPriorityQueue[int](func(a, b int) bool { return a < b })
+	want := make(map[int]int)
+
+	// Randomly set, change and remove keys, checking against a map
+	for range 2000 {
+		key := r.IntN(50)
+		switch r.IntN(4) {
+		case 0:
+			delete(want, key)
+			pq.Remove(key)
+		default:
+			prio := r.IntN(1000)
+			want[key] = prio
+			pq.Set(key, prio)
+		}
+		assert.Equal(t, len(want), pq.Len())
+	}
+
+	prev := -1
+	for pq.Len() > 0 {
+		key, prio := pq.Pop()
+		assert.Equal(t, want[key], prio)
+		assert.GreaterOrEqual(t, prio, prev)
+		prev = prio
+		delete(want, key)
+	}
+	assert.Empty(t, want)
+}
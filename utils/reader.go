@@ -0,0 +1,124 @@
+package utils
+
+import (
+	"bufio"
+	"cmp"
+	"fmt"
+	"io"
+	"iter"
+	"strings"
+)
+
+// LineReader reads an input one line at a time, so that only one line needs to be in
+// memory at once. Use it like a bufio.Scanner:
+//
+//	lr := NewLineReader(f, 0)
+//	for line_num, line := range lr.Lines() {
+//		...
+//	}
+//	if err := lr.Err(); err != nil {
+//		...
+//	}
+type LineReader struct {
+	scanner  *bufio.Scanner
+	line_num int
+	err      error
+}
+
+// NewLineReader reads lines from `r`. A line longer than `max_len` bytes is an error. If
+// `max_len` is 0, bufio.MaxScanTokenSize (64KiB) is used.
+func NewLineReader(r io.Reader, max_len int) *LineReader {
+	if max_len <= 0 {
+		max_len = bufio.MaxScanTokenSize
+	}
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, min(max_len, 4096)), max_len)
+	return &LineReader{scanner: scanner}
+}
+
+// Lines yields each line with its line number, starting from 1. Trailing whitespace,
+// including the "\r" of Windows line endings, is removed. Blank lines are not skipped.
+func (lr *LineReader) Lines() iter.Seq2[int, string] {
+	return func(yield func(int, string) bool) {
+		for lr.scanner.Scan() {
+			lr.line_num++
+			if !yield(lr.line_num, strings.TrimRight(lr.scanner.Text(), " \t\r")) {
+				return
+			}
+		}
+		if err := lr.scanner.Err(); err != nil {
+			lr.err = fmt.Errorf("line %d: %w", lr.line_num+1, err)
+		}
+	}
+}
+
+// Err returns the first error found while reading, if any. Check it after the loop over
+// Lines finishes.
+func (lr *LineReader) Err() error {
+	return lr.err
+}
+
+// RecordReader reads an input one record at a time, where records are separated by blank
+// lines. It follows the same rules as Sections, but only one record is in memory at once.
+type RecordReader struct {
+	lines   *LineReader
+	max_len int
+	err     error
+}
+
+// NewRecordReader reads records from `r`. A line or a whole record longer than `max_len`
+// bytes is an error. If `max_len` is 0, bufio.MaxScanTokenSize (64KiB) is used.
+func NewRecordReader(r io.Reader, max_len int) *RecordReader {
+	if max_len <= 0 {
+		max_len = bufio.MaxScanTokenSize
+	}
+	return &RecordReader{NewLineReader(r, max_len), max_len, nil}
+}
+
+// Records yields each record as a Section, holding its text and starting line number
+func (rr *RecordReader) Records() iter.Seq[Section] {
+	return func(yield func(Section) bool) {
+		var sb strings.Builder
+		start := 0
+
+		// Hand out the current record (if there is one), and start a new one
+		flush := func() bool {
+			if sb.Len() == 0 {
+				return true
+			}
+			s := Section{sb.String(), start}
+			sb.Reset()
+			return yield(s)
+		}
+
+		for line_num, line := range rr.lines.Lines() {
+			if line == "" {
+				if !flush() {
+					return
+				}
+				continue
+			}
+
+			if sb.Len() == 0 {
+				start = line_num
+			} else {
+				sb.WriteByte('\n')
+			}
+			if sb.Len()+len(line) > rr.max_len {
+				rr.err = fmt.Errorf("line %d: record starting on line %d: %w", line_num, start, bufio.ErrTooLong)
+				return
+			}
+			sb.WriteString(line)
+		}
+		if rr.lines.Err() != nil {
+			return
+		}
+		flush()
+	}
+}
+
+// Err returns the first error found while reading, if any. Check it after the loop over
+// Records finishes.
+func (rr *RecordReader) Err() error {
+	return cmp.Or(rr.err, rr.lines.Err())
+}
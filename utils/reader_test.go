@@ -0,0 +1,87 @@
+package utils
+
+import (
+	"bufio"
+	"errors"
+	"strings"
+	"testing"
+	"testing/iotest"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLineReader(t *testing.T) {
+	// Read one byte at a time to make sure lines are put back together
+	r := iotest.OneByteReader(strings.NewReader("3   4\r\n4   3 \n\n2   5"))
+	lr := NewLineReader(r, 0)
+
+	var nums []int
+	var lines []string
+	for line_num, line := range lr.Lines() {
+		nums = append(nums, line_num)
+		lines = append(lines, line)
+	}
+	assert.NoError(t, lr.Err())
+	assert.Equal(t, []int{1, 2, 3, 4}, nums)
+	assert.Equal(t, []string{"3   4", "4   3", "", "2   5"}, lines)
+}
+
+func TestLineReaderErrors(t *testing.T) {
+	// A line that is too long
+	lr := NewLineReader(strings.NewReader("abc\nabcdefghij\nabc"), 8)
+	count := 0
+	for range lr.Lines() {
+		count++
+	}
+	assert.Equal(t, 1, count)
+	assert.ErrorIs(t, lr.Err(), bufio.ErrTooLong)
+	assert.EqualError(t, lr.Err(), "line 2: bufio.Scanner: token too long")
+
+	// An error from the reader itself
+	boom := errors.New("boom")
+	lr = NewLineReader(iotest.ErrReader(boom), 0)
+	for range lr.Lines() {
+		t.Fatal("should not get any lines")
+	}
+	assert.ErrorIs(t, lr.Err(), boom)
+}
+
+func TestRecordReader(t *testing.T) {
+	// Should give the same answers as Sections
+	inputs := []string{
+		"a\nb\n\nc",
+		"a\r\nb\r\n\r\nc\r\n",
+		"\n\na\n\n\n\nb\n\n",
+		"a  \n \t \nb\t",
+		"",
+	}
+	for _, input := range inputs {
+		rr := NewRecordReader(iotest.HalfReader(strings.NewReader(input)), 0)
+		got := make([]Section, 0)
+		for s := range rr.Records() {
+			got = append(got, s)
+		}
+		assert.NoError(t, rr.Err())
+		assert.Equal(t, Sections(input), got)
+	}
+}
+
+func TestRecordReaderErrors(t *testing.T) {
+	// Each line fits, but the record does not
+	rr := NewRecordReader(strings.NewReader("ab\n\nabc\nabc\nabc"), 8)
+	var got []string
+	for s := range rr.Records() {
+		got = append(got, s.Text)
+	}
+	assert.Equal(t, []string{"ab"}, got)
+	assert.ErrorIs(t, rr.Err(), bufio.ErrTooLong)
+	assert.EqualError(t, rr.Err(), "line 5: record starting on line 3: bufio.Scanner: token too long")
+
+	// Stopping early is not an error
+	rr = NewRecordReader(strings.NewReader("a\n\nb"), 0)
+	for s := range rr.Records() {
+		assert.Equal(t, "a", s.Text)
+		break
+	}
+	assert.NoError(t, rr.Err())
+}
@@ -0,0 +1,316 @@
+package utils
+
+import (
+	"iter"
+	"slices"
+)
+
+// The search functions in this file work on any comparable state, e.g. a Vec2 on a Grid,
+// a node in a DiGraph, or a (position, direction) pair. The graph is never built up
+// front; instead a `neighbors` callback yields the states that can be reached in one
+// step. Every search takes a slice of start states, so that searching from several
+// places at once is the same as searching from one.
+//
+// Weighted searches take a callback that yields (neighbor, cost) pairs. Costs must not be
+// negative.
+
+// build_path walks back through `parents` from `goal` to a start state, and returns the
+// path in order from start to goal.
+func build_path[S comparable](parents map[S]S, goal S) []S {
+	path := []S{goal}
+	for {
+		p, ok := parents[path[len(path)-1]]
+		if !ok {
+			break
+		}
+		path = append(path, p)
+	}
+	slices.Reverse(path)
+	return path
+}
+
+// BFS does a breadth first search from `starts`, and returns the shortest path (fewest
+// steps) to the closest state where `is_goal` is true. The path includes both the start
+// and the goal. If no goal can be reached, it returns false.
+func BFS[S comparable](starts []S, neighbors func(S) iter.Seq[S], is_goal func(S) bool) ([]S, bool) {
+	parents := make(map[S]S)
+	seen := make(map[S]struct{}, len(starts))
+	queue := make([]S, 0, len(starts))
+	for _, s := range starts {
+		if _, ok := seen[s]; ok {
+			continue
+		}
+		seen[s] = struct{}{}
+		queue = append(queue, s)
+	}
+
+	for len(queue) > 0 {
+		curr := queue[0]
+		queue = queue[1:]
+
+		if is_goal(curr) {
+			return build_path(parents, curr), true
+		}
+
+		for next := range neighbors(curr) {
+			if _, ok := seen[next]; ok {
+				continue
+			}
+			seen[next] = struct{}{}
+			parents[next] = curr
+			queue = append(queue, next)
+		}
+	}
+
+	return nil, false
+}
+
+// DFS does a depth first search from `starts`, and returns the first path it finds to a
+// state where `is_goal` is true. Unlike BFS, the path is not necessarily the shortest.
+// If no goal can be reached, it returns false.
+func DFS[S comparable](starts []S, neighbors func(S) iter.Seq[S], is_goal func(S) bool) ([]S, bool) {
+	parents := make(map[S]S)
+	seen := make(map[S]struct{})
+
+	// Push the starts in reverse, so that the first one is searched first
+	stack := slices.Clone(starts)
+	slices.Reverse(stack)
+
+	for len(stack) > 0 {
+		curr := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+
+		if _, ok := seen[curr]; ok {
+			continue
+		}
+		seen[curr] = struct{}{}
+
+		if is_goal(curr) {
+			return build_path(parents, curr), true
+		}
+
+		// Collect the neighbors so they can be pushed in reverse, which keeps the
+		// search in the same order the neighbors were given in
+		next_states := make([]S, 0)
+		for next := range neighbors(curr) {
+			if _, ok := seen[next]; !ok {
+				next_states = append(next_states, next)
+			}
+		}
+		for _, next := range slices.Backward(next_states) {
+			parents[next] = curr
+			stack = append(stack, next)
+		}
+	}
+
+	return nil, false
+}
+
+// ShortestPaths holds the result of searching out from a set of start states to every
+// reachable state. Preds keeps *every* predecessor that lies on a shortest path, so that
+// ties can be followed as well.
+type ShortestPaths[S comparable] struct {
+	// The cost of the cheapest path from any start to each reachable state
+	Dist map[S]int
+
+	// All of the states that come right before each state on some cheapest path. The
+	// starts have no predecessors.
+	Preds map[S][]S
+}
+
+// Reached checks if a state could be reached from any of the starts
+func (sp ShortestPaths[S]) Reached(s S) bool {
+	_, ok := sp.Dist[s]
+	return ok
+}
+
+// Path returns one of the cheapest paths from a start to `goal`, or nil if `goal` was
+// not reached. When there are ties, it follows the first predecessor found.
+func (sp ShortestPaths[S]) Path(goal S) []S {
+	if !sp.Reached(goal) {
+		return nil
+	}
+	path := []S{goal}
+	for preds := sp.Preds[goal]; len(preds) > 0; preds = sp.Preds[preds[0]] {
+		path = append(path, preds[0])
+	}
+	slices.Reverse(path)
+	return path
+}
+
+// OnAnyPath returns every state that lies on at least one cheapest path to any of
+// `goals`, including the starts and goals themselves.
+func (sp ShortestPaths[S]) OnAnyPath(goals ...S) map[S]struct{} {
+	res := make(map[S]struct{})
+	stack := make([]S, 0, len(goals))
+	for _, g := range goals {
+		if sp.Reached(g) {
+			stack = append(stack, g)
+		}
+	}
+	for len(stack) > 0 {
+		curr := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+		if _, ok := res[curr]; ok {
+			continue
+		}
+		res[curr] = struct{}{}
+		stack = append(stack, sp.Preds[curr]...)
+	}
+	return res
+}
+
+// CountPaths returns how many distinct cheapest paths lead from the starts to `goal`
+func (sp ShortestPaths[S]) CountPaths(goal S) int {
+	counts := make(map[S]int)
+	var count func(s S) int
+	count = func(s S) int {
+		if n, ok := counts[s]; ok {
+			return n
+		}
+		preds := sp.Preds[s]
+		n := 0
+		if len(preds) == 0 {
+			n = 1
+		}
+		for _, p := range preds {
+			n += count(p)
+		}
+		counts[s] = n
+		return n
+	}
+
+	if !sp.Reached(goal) {
+		return 0
+	}
+	return count(goal)
+}
+
+// BFSAll does a breadth first search from `starts` to every reachable state, where each
+// step has a cost of 1.
+func BFSAll[S comparable](starts []S, neighbors func(S) iter.Seq[S]) ShortestPaths[S] {
+	sp := ShortestPaths[S]{make(map[S]int), make(map[S][]S)}
+	queue := make([]S, 0, len(starts))
+	for _, s := range starts {
+		if _, ok := sp.Dist[s]; ok {
+			continue
+		}
+		sp.Dist[s] = 0
+		queue = append(queue, s)
+	}
+
+	for len(queue) > 0 {
+		curr := queue[0]
+		queue = queue[1:]
+		next_dist := sp.Dist[curr] + 1
+
+		for next := range neighbors(curr) {
+			d, ok := sp.Dist[next]
+			if !ok {
+				sp.Dist[next] = next_dist
+				sp.Preds[next] = []S{curr}
+				queue = append(queue, next)
+			} else if d == next_dist {
+				sp.Preds[next] = append(sp.Preds[next], curr)
+			}
+		}
+	}
+
+	return sp
+}
+
+// best_first is the shared core of Dijkstra and A*. It returns the cheapest path to the
+// first goal that comes off of the queue. The queue is ordered by the cost so far, plus
+// the heuristic.
+func best_first[S comparable](
+	starts []S,
+	neighbors func(S) iter.Seq2[S, int],
+	is_goal func(S) bool,
+	heuristic func(S) int,
+) ([]S, int, bool) {
+	parents := make(map[S]S)
+	costs := make(map[S]int)
+	done := make(map[S]struct{})
+	queue := NewIndexedPriorityQueue[S](func(a, b int) bool { return a < b })
+
+	for _, s := range starts {
+		costs[s] = 0
+		queue.Set(s, heuristic(s))
+	}
+
+	for queue.Len() > 0 {
+		curr, _ := queue.Pop()
+		done[curr] = struct{}{}
+
+		if is_goal(curr) {
+			return build_path(parents, curr), costs[curr], true
+		}
+
+		for next, step_cost := range neighbors(curr) {
+			if _, ok := done[next]; ok {
+				continue
+			}
+			next_cost := costs[curr] + step_cost
+			if c, ok := costs[next]; ok && c <= next_cost {
+				continue
+			}
+			costs[next] = next_cost
+			parents[next] = curr
+			queue.Set(next, next_cost+heuristic(next))
+		}
+	}
+
+	return nil, 0, false
+}
+
+// Dijkstra finds the cheapest path from any of `starts` to the closest state where
+// `is_goal` is true. It returns the path (including the start and the goal) and its
+// cost, or false if no goal can be reached.
+func Dijkstra[S comparable](starts []S, neighbors func(S) iter.Seq2[S, int], is_goal func(S) bool) ([]S, int, bool) {
+	return best_first(starts, neighbors, is_goal, func(S) int { return 0 })
+}
+
+// AStar is the same as Dijkstra, but uses `heuristic` to search towards the goal first.
+// The heuristic must never overestimate the remaining cost to the goal, and must not
+// drop by more than the cost of a step, or the path returned may not be the cheapest.
+// E.g. Manhattan distance on a grid where each step costs at least 1.
+func AStar[S comparable](
+	starts []S,
+	neighbors func(S) iter.Seq2[S, int],
+	is_goal func(S) bool,
+	heuristic func(S) int,
+) ([]S, int, bool) {
+	return best_first(starts, neighbors, is_goal, heuristic)
+}
+
+// DijkstraAll finds the cheapest path from any of `starts` to every reachable state, and
+// keeps every predecessor that ties for the cheapest path. Costs must be greater than 0,
+// or the predecessors could loop back on themselves.
+func DijkstraAll[S comparable](starts []S, neighbors func(S) iter.Seq2[S, int]) ShortestPaths[S] {
+	sp := ShortestPaths[S]{make(map[S]int), make(map[S][]S)}
+	queue := NewIndexedPriorityQueue[S](func(a, b int) bool { return a < b })
+
+	for _, s := range starts {
+		sp.Dist[s] = 0
+		queue.Set(s, 0)
+	}
+
+	for queue.Len() > 0 {
+		curr, curr_cost := queue.Pop()
+
+		for next, step_cost := range neighbors(curr) {
+			next_cost := curr_cost + step_cost
+			c, ok := sp.Dist[next]
+			switch {
+			case !ok || next_cost < c:
+				sp.Dist[next] = next_cost
+				sp.Preds[next] = []S{curr}
+				queue.Set(next, next_cost)
+			case next_cost == c && !slices.Contains(sp.Preds[next], curr):
+				sp.Preds[next] = append(sp.Preds[next], curr)
+			}
+		}
+	}
+
+	return sp
+}
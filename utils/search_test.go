@@ -0,0 +1,240 @@
+package utils
+
+import (
+	"iter"
+	"slices"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// The maze used by the grid tests. S is the start, E is the end.
+const test_maze = `S..#....
+.#.#.##.
+.#...#..
+.####.#.
+......#E`
+
+// open_neighbors yields the open cells next to a cell in a maze, in the order up,
+// right, down, left
+func open_neighbors(g Grid[byte]) func(Vec2) iter.Seq[Vec2] {
+	return func(v Vec2) iter.Seq[Vec2] {
+		return func(yield func(Vec2) bool) {
+			for r, c := range g.Neighbors4(v.Y, v.X) {
+				if g.At(r, c) == '#' {
+					continue
+				}
+				if !yield(Vec2{c, r}) {
+					return
+				}
+			}
+		}
+	}
+}
+
+// weighted turns unweighted neighbors into neighbors where each step has the same cost
+func weighted[S comparable](neighbors func(S) iter.Seq[S], cost int) func(S) iter.Seq2[S, int] {
+	return func(s S) iter.Seq2[S, int] {
+		return func(yield func(S, int) bool) {
+			for next := range neighbors(s) {
+				if !yield(next, cost) {
+					return
+				}
+			}
+		}
+	}
+}
+
+// is checks for one particular state
+func is[S comparable](goal S) func(S) bool {
+	return func(s S) bool { return s == goal }
+}
+
+// check_path makes sure that each step of a path is to a neighbor
+func check_path[S comparable](t *testing.T, path []S, neighbors func(S) iter.Seq[S]) {
+	t.Helper()
+	for idx := 1; idx < len(path); idx++ {
+		assert.Contains(t, slices.Collect(neighbors(path[idx-1])), path[idx])
+	}
+}
+
+func TestBFSGrid(t *testing.T) {
+	g := ParseGrid(test_maze, to_byte)
+	neighbors := open_neighbors(g)
+	start, end := Vec2{0, 0}, Vec2{7, 4}
+
+	path, ok := BFS([]Vec2{start}, neighbors, is(end))
+	assert.True(t, ok)
+	assert.Equal(t, start, path[0])
+	assert.Equal(t, end, path[len(path)-1])
+	assert.Len(t, path, 16)
+	check_path(t, path, neighbors)
+
+	// Walled in
+	g.Set(3, 7, '#')
+	_, ok = BFS([]Vec2{start}, neighbors, is(end))
+	assert.False(t, ok)
+}
+
+func TestBFSMultiSource(t *testing.T) {
+	g := ParseGrid(test_maze, to_byte)
+	neighbors := open_neighbors(g)
+
+	// The second start is much closer, so that is where the path starts from
+	path, ok := BFS([]Vec2{{0, 0}, {7, 0}}, neighbors, is(Vec2{7, 4}))
+	assert.True(t, ok)
+	assert.Equal(t, []Vec2{{7, 0}, {7, 1}, {7, 2}, {7, 3}, {7, 4}}, path)
+}
+
+func TestDFSGrid(t *testing.T) {
+	g := ParseGrid(test_maze, to_byte)
+	neighbors := open_neighbors(g)
+	start, end := Vec2{0, 0}, Vec2{7, 4}
+
+	path, ok := DFS([]Vec2{start}, neighbors, is(end))
+	assert.True(t, ok)
+	assert.Equal(t, start, path[0])
+	assert.Equal(t, end, path[len(path)-1])
+	assert.GreaterOrEqual(t, len(path), 16)
+	check_path(t, path, neighbors)
+
+	_, ok = DFS([]Vec2{start}, neighbors, is(Vec2{3, 0}))
+	assert.False(t, ok)
+}
+
+func TestBFSAllGrid(t *testing.T) {
+	g := ParseGrid("...\n...\n...", to_byte)
+	sp := BFSAll([]Vec2{{0, 0}}, open_neighbors(g))
+
+	assert.Equal(t, 4, sp.Dist[Vec2{2, 2}])
+	assert.Equal(t, 2, sp.Dist[Vec2{1, 1}])
+
+	// There are 6 ways to go 2 right and 2 down
+	assert.Equal(t, 6, sp.CountPaths(Vec2{2, 2}))
+	assert.Len(t, sp.OnAnyPath(Vec2{2, 2}), 9)
+	assert.Len(t, sp.OnAnyPath(Vec2{2, 0}), 3)
+	assert.Len(t, sp.Path(Vec2{2, 2}), 5)
+	assert.Nil(t, sp.Path(Vec2{5, 5}))
+	assert.Equal(t, 0, sp.CountPaths(Vec2{5, 5}))
+}
+
+// test_digraph builds a weighted graph where the cheapest way from A to E is not the
+// one with the fewest steps
+//
+//	A -1-> B -1-> C -1-> E
+//	A -5-> E
+//	A -2-> D -1-> E
+func test_digraph() (DiGraph[string], map[[2]string]int) {
+	costs := map[[2]string]int{
+		{"A", "B"}: 1, {"B", "C"}: 1, {"C", "E"}: 1,
+		{"A", "E"}: 5,
+		{"A", "D"}: 2, {"D", "E"}: 1,
+	}
+	g := NewDiGraph[string]()
+	for _, e := range [][2]string{{"A", "B"}, {"B", "C"}, {"C", "E"}, {"A", "E"}, {"A", "D"}, {"D", "E"}} {
+		g.AddEdge(e[0], e[1])
+	}
+	return g, costs
+}
+
+func TestSearchDiGraph(t *testing.T) {
+	g, costs := test_digraph()
+	weighted_neighbors := func(n string) iter.Seq2[string, int] {
+		return func(yield func(string, int) bool) {
+			for next := range g.Successors(n) {
+				if !yield(next, costs[[2]string{n, next}]) {
+					return
+				}
+			}
+		}
+	}
+
+	// Fewest steps
+	path, ok := BFS([]string{"A"}, g.Successors, is("E"))
+	assert.True(t, ok)
+	assert.Equal(t, []string{"A", "E"}, path)
+
+	// Depth first follows the first edge all the way
+	path, ok = DFS([]string{"A"}, g.Successors, is("E"))
+	assert.True(t, ok)
+	assert.Equal(t, []string{"A", "B", "C", "E"}, path)
+
+	// Cheapest, where A-B-C-E and A-D-E tie on 3
+	path, cost, ok := Dijkstra([]string{"A"}, weighted_neighbors, is("E"))
+	assert.True(t, ok)
+	assert.Equal(t, 3, cost)
+	assert.Contains(t, [][]string{{"A", "B", "C", "E"}, {"A", "D", "E"}}, path)
+
+	sp := DijkstraAll([]string{"A"}, weighted_neighbors)
+	assert.Equal(t, map[string]int{"A": 0, "B": 1, "C": 2, "D": 2, "E": 3}, sp.Dist)
+	assert.ElementsMatch(t, []string{"C", "D"}, sp.Preds["E"])
+	assert.Equal(t, 2, sp.CountPaths("E"))
+	assert.Len(t, sp.OnAnyPath("E"), 5)
+
+	// A* with the cheapest remaining cost to E as the heuristic finds the same cost
+	remaining := map[string]int{"A": 3, "B": 2, "C": 1, "D": 1, "E": 0}
+	heuristic := func(n string) int { return remaining[n] }
+	path, cost, ok = AStar([]string{"A"}, weighted_neighbors, is("E"), heuristic)
+	assert.True(t, ok)
+	assert.Equal(t, 3, cost)
+	assert.Contains(t, [][]string{{"A", "B", "C", "E"}, {"A", "D", "E"}}, path)
+
+	// Every state by fewest steps, where E is only one step away
+	bfs := BFSAll([]string{"A"}, g.Successors)
+	assert.Equal(t, map[string]int{"A": 0, "B": 1, "C": 2, "D": 1, "E": 1}, bfs.Dist)
+	assert.Equal(t, []string{"A"}, bfs.Preds["E"])
+	assert.Equal(t, []string{"A", "E"}, bfs.Path("E"))
+	assert.Equal(t, 1, bfs.CountPaths("E"))
+	assert.Equal(t, []string{"A", "B", "C"}, bfs.Path("C"))
+
+	// Nothing leads back to A
+	_, _, ok = Dijkstra([]string{"E"}, weighted_neighbors, is("A"))
+	assert.False(t, ok)
+	_, ok = BFS([]string{"E"}, g.Successors, is("A"))
+	assert.False(t, ok)
+	_, _, ok = AStar([]string{"E"}, weighted_neighbors, is("A"), heuristic)
+	assert.False(t, ok)
+	bfs = BFSAll([]string{"E"}, g.Successors)
+	assert.Equal(t, map[string]int{"E": 0}, bfs.Dist)
+	assert.False(t, bfs.Reached("A"))
+	assert.Nil(t, bfs.Path("A"))
+	assert.Equal(t, 0, bfs.CountPaths("A"))
+
+	// Searching backwards from E over the predecessors
+	path, ok = BFS([]string{"E"}, g.Predecessors, is("A"))
+	assert.True(t, ok)
+	assert.Equal(t, []string{"E", "A"}, path)
+}
+
+func TestDijkstraAndAStarGrid(t *testing.T) {
+	g := ParseGrid(test_maze, to_byte)
+	neighbors := weighted(open_neighbors(g), 1)
+	start, end := Vec2{0, 0}, Vec2{7, 4}
+
+	d_path, d_cost, ok := Dijkstra([]Vec2{start}, neighbors, is(end))
+	assert.True(t, ok)
+	assert.Equal(t, 15, d_cost)
+	assert.Len(t, d_path, 16)
+
+	a_path, a_cost, ok := AStar([]Vec2{start}, neighbors, is(end), end.Manhattan)
+	assert.True(t, ok)
+	assert.Equal(t, d_cost, a_cost)
+	assert.Len(t, a_path, 16)
+	check_path(t, a_path, open_neighbors(g))
+
+	// Multiple goals, and the closest is found
+	_, cost, ok := Dijkstra([]Vec2{start}, neighbors, func(v Vec2) bool { return v.X == 4 })
+	assert.True(t, ok)
+	assert.Equal(t, 6, cost)
+
+	// Multiple starts
+	_, cost, ok = AStar([]Vec2{start, {7, 2}}, neighbors, is(end), end.Manhattan)
+	assert.True(t, ok)
+	assert.Equal(t, 2, cost)
+
+	// The same distances as BFS when every step costs 1
+	sp := DijkstraAll([]Vec2{start}, neighbors)
+	bfs := BFSAll([]Vec2{start}, open_neighbors(g))
+	assert.Equal(t, bfs.Dist, sp.Dist)
+	assert.Equal(t, bfs.CountPaths(end), sp.CountPaths(end))
+}
@@ -0,0 +1,65 @@
+package utils
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Section is one block of an input, where blocks are separated by blank lines
+type Section struct {
+	// The lines of the block joined with "\n", with no trailing newline
+	Text string
+
+	// The line number in the original input that the block starts on, starting from 1
+	Line int
+}
+
+// Lines splits the section into its lines
+func (s Section) Lines() []string {
+	return strings.Split(s.Text, "\n")
+}
+
+// Sections splits an input into blocks separated by one or more blank lines. Windows
+// line endings are turned into "\n", trailing whitespace is removed from every line, and
+// a line with only whitespace counts as blank. Blank lines at the start and end of the
+// input are ignored.
+func Sections(raw_text string) []Section {
+	raw_text = strings.ReplaceAll(raw_text, "\r\n", "\n")
+
+	sections := make([]Section, 0)
+	var block []string
+	start := 0
+
+	// Add the current block (if there is one) to the list of sections
+	flush := func() {
+		if len(block) > 0 {
+			sections = append(sections, Section{strings.Join(block, "\n"), start})
+			block = block[:0]
+		}
+	}
+
+	for idx, line := range strings.Split(raw_text, "\n") {
+		line = strings.TrimRight(line, " \t\r")
+		if line == "" {
+			flush()
+			continue
+		}
+		if len(block) == 0 {
+			start = idx + 1
+		}
+		block = append(block, line)
+	}
+	flush()
+
+	return sections
+}
+
+// SectionsN is Sections, but returns an error if the input does not have exactly `n`
+// sections.
+func SectionsN(raw_text string, n int) ([]Section, error) {
+	sections := Sections(raw_text)
+	if len(sections) != n {
+		return sections, fmt.Errorf("expected %d sections separated by blank lines, found %d", n, len(sections))
+	}
+	return sections, nil
+}
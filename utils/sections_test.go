@@ -0,0 +1,45 @@
+package utils
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSections(t *testing.T) {
+	tests := []struct {
+		name string
+		raw  string
+		want []Section
+	}{
+		{"simple", "a\nb\n\nc", []Section{{"a\nb", 1}, {"c", 4}}},
+		{"trailing newline", "a\n\nb\n", []Section{{"a", 1}, {"b", 3}}},
+		{"windows", "a\r\nb\r\n\r\nc\r\n", []Section{{"a\nb", 1}, {"c", 4}}},
+		{"whitespace blank line", "a  \n \t \nb\t", []Section{{"a", 1}, {"b", 3}}},
+		{"many blank lines", "\n\na\n\n\n\nb\n\n", []Section{{"a", 3}, {"b", 7}}},
+		{"keeps leading spaces", "  a\n b", []Section{{"  a\n b", 1}}},
+		{"empty", "", []Section{}},
+		{"only blank", "\n \n\r\n", []Section{}},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.want, Sections(tc.raw))
+		})
+	}
+}
+
+func TestSectionLines(t *testing.T) {
+	s := Sections("47|53\n97|13\n\n75,47\n")
+	assert.Equal(t, []string{"47|53", "97|13"}, s[0].Lines())
+	assert.Equal(t, []string{"75,47"}, s[1].Lines())
+}
+
+func TestSectionsN(t *testing.T) {
+	s, err := SectionsN("a\n\nb", 2)
+	assert.NoError(t, err)
+	assert.Len(t, s, 2)
+
+	s, err = SectionsN("a\n\nb\n\nc", 2)
+	assert.EqualError(t, err, "expected 2 sections separated by blank lines, found 3")
+	assert.Len(t, s, 3)
+}
@@ -0,0 +1,152 @@
+package utils
+
+import (
+	"iter"
+	"maps"
+	"slices"
+)
+
+// Set is an unordered collection of unique items. It is a plain map underneath, so it
+// can be used anywhere a `map[T]struct{}` is expected, and `len(s)` is its size.
+type Set[T comparable] map[T]struct{}
+
+// NewSet creates a set holding the given items
+func NewSet[T comparable](items ...T) Set[T] {
+	s := make(Set[T], len(items))
+	for _, item := range items {
+		s[item] = struct{}{}
+	}
+	return s
+}
+
+// CollectSet creates a set from every item in the sequence
+func CollectSet[T comparable](items iter.Seq[T]) Set[T] {
+	s := make(Set[T])
+	for item := range items {
+		s[item] = struct{}{}
+	}
+	return s
+}
+
+// Add puts the items in the set
+func (s Set[T]) Add(items ...T) {
+	for _, item := range items {
+		s[item] = struct{}{}
+	}
+}
+
+// Remove takes the items out of the set, if they are in it
+func (s Set[T]) Remove(items ...T) {
+	for _, item := range items {
+		delete(s, item)
+	}
+}
+
+// Contains checks if an item is in the set
+func (s Set[T]) Contains(item T) bool {
+	_, ok := s[item]
+	return ok
+}
+
+// Clone returns a copy of the set
+func (s Set[T]) Clone() Set[T] {
+	return maps.Clone(s)
+}
+
+// All yields every item in the set, in no particular order
+func (s Set[T]) All() iter.Seq[T] {
+	return maps.Keys(s)
+}
+
+// Sorted yields every item in the set, in the order given by `cmp`
+func (s Set[T]) Sorted(cmp func(a, b T) int) iter.Seq[T] {
+	return slices.Values(slices.SortedFunc(maps.Keys(s), cmp))
+}
+
+// Union returns a new set with every item that is in either set
+func (s Set[T]) Union(other Set[T]) Set[T] {
+	res := make(Set[T], max(len(s), len(other)))
+	for item := range s {
+		res[item] = struct{}{}
+	}
+	for item := range other {
+		res[item] = struct{}{}
+	}
+	return res
+}
+
+// Intersection returns a new set with the items that are in both sets
+func (s Set[T]) Intersection(other Set[T]) Set[T] {
+	// Loop over the smaller set
+	small, big := s, other
+	if len(small) > len(big) {
+		small, big = big, small
+	}
+	res := make(Set[T])
+	for item := range small {
+		if _, ok := big[item]; ok {
+			res[item] = struct{}{}
+		}
+	}
+	return res
+}
+
+// Difference returns a new set with the items in s that are not in other
+func (s Set[T]) Difference(other Set[T]) Set[T] {
+	res := make(Set[T])
+	for item := range s {
+		if _, ok := other[item]; !ok {
+			res[item] = struct{}{}
+		}
+	}
+	return res
+}
+
+// SymmetricDifference returns a new set with the items that are in exactly one of the
+// sets
+func (s Set[T]) SymmetricDifference(other Set[T]) Set[T] {
+	res := s.Difference(other)
+	for item := range other {
+		if _, ok := s[item]; !ok {
+			res[item] = struct{}{}
+		}
+	}
+	return res
+}
+
+// IsSubset checks if every item in s is also in other
+func (s Set[T]) IsSubset(other Set[T]) bool {
+	if len(s) > len(other) {
+		return false
+	}
+	for item := range s {
+		if _, ok := other[item]; !ok {
+			return false
+		}
+	}
+	return true
+}
+
+// IsSuperset checks if every item in other is also in s
+func (s Set[T]) IsSuperset(other Set[T]) bool {
+	return other.IsSubset(s)
+}
+
+// Equal checks if both sets hold exactly the same items
+func (s Set[T]) Equal(other Set[T]) bool {
+	return len(s) == len(other) && s.IsSubset(other)
+}
+
+// IsDisjoint checks if the sets have no items in common
+func (s Set[T]) IsDisjoint(other Set[T]) bool {
+	small, big := s, other
+	if len(small) > len(big) {
+		small, big = big, small
+	}
+	for item := range small {
+		if _, ok := big[item]; ok {
+			return false
+		}
+	}
+	return true
+}
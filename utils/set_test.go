@@ -0,0 +1,93 @@
+package utils
+
+import (
+	"cmp"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewSet(t *testing.T) {
+	s := NewSet(1, 2, 3, 2)
+	assert.Len(t, s, 3)
+	assert.True(t, s.Contains(2))
+	assert.False(t, s.Contains(4))
+
+	s.Add(4, 5)
+	s.Remove(1, 9)
+	assert.Equal(t, NewSet(2, 3, 4, 5), s)
+
+	c := s.Clone()
+	c.Add(10)
+	assert.False(t, s.Contains(10))
+
+	assert.Equal(t, NewSet("a", "b"), CollectSet(slices.Values([]string{"b", "a", "b"})))
+
+	// Still usable as a plain map
+	var m map[int]struct{} = s
+	assert.Len(t, m, 4)
+}
+
+func TestSetAlgebra(t *testing.T) {
+	a := NewSet(1, 2, 3, 4)
+	b := NewSet(3, 4, 5)
+
+	assert.Equal(t, NewSet(1, 2, 3, 4, 5), a.Union(b))
+	assert.Equal(t, NewSet(3, 4), a.Intersection(b))
+	assert.Equal(t, NewSet(3, 4), b.Intersection(a))
+	assert.Equal(t, NewSet(1, 2), a.Difference(b))
+	assert.Equal(t, NewSet(5), b.Difference(a))
+	assert.Equal(t, NewSet(1, 2, 5), a.SymmetricDifference(b))
+
+	// None of the operations change the inputs
+	assert.Equal(t, NewSet(1, 2, 3, 4), a)
+	assert.Equal(t, NewSet(3, 4, 5), b)
+
+	empty := NewSet[int]()
+	assert.Equal(t, a, a.Union(empty))
+	assert.Empty(t, a.Intersection(empty))
+	assert.Equal(t, a, a.Difference(empty))
+}
+
+func TestSetComparisons(t *testing.T) {
+	a := NewSet(1, 2, 3)
+	tests := []struct {
+		name                    string
+		other                   Set[int]
+		subset, superset, equal bool
+		disjoint                bool
+	}{
+		{"same", NewSet(3, 2, 1), true, true, true, false},
+		{"bigger", NewSet(1, 2, 3, 4), true, false, false, false},
+		{"smaller", NewSet(1, 3), false, true, false, false},
+		{"overlap", NewSet(3, 4), false, false, false, false},
+		{"disjoint", NewSet(7, 8), false, false, false, true},
+		{"empty", NewSet[int](), false, true, false, true},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.subset, a.IsSubset(tc.other))
+			assert.Equal(t, tc.superset, a.IsSuperset(tc.other))
+			assert.Equal(t, tc.equal, a.Equal(tc.other))
+			assert.Equal(t, tc.disjoint, a.IsDisjoint(tc.other))
+		})
+	}
+}
+
+func TestSetIteration(t *testing.T) {
+	s := NewSet("pear", "fig", "apple", "kiwi")
+	assert.ElementsMatch(t, []string{"apple", "fig", "kiwi", "pear"}, slices.Collect(s.All()))
+	assert.Equal(t, []string{"apple", "fig", "kiwi", "pear"}, slices.Collect(s.Sorted(strings.Compare)))
+
+	// Sort by length, then alphabetically
+	by_len := func(a, b string) int { return cmp.Or(cmp.Compare(len(a), len(b)), strings.Compare(a, b)) }
+	assert.Equal(t, []string{"fig", "kiwi", "pear", "apple"}, slices.Collect(s.Sorted(by_len)))
+
+	// Stopping early
+	for item := range s.Sorted(strings.Compare) {
+		assert.Equal(t, "apple", item)
+		break
+	}
+}
@@ -0,0 +1,164 @@
+package utils
+
+// UnionFind keeps track of which items are connected to each other, splitting them into
+// disjoint components. Joining two components and finding an item's component both
+// take nearly constant time, using union by size and path compression.
+type UnionFind[T comparable] struct {
+	// Each item gets an index the first time it is seen
+	ids   map[T]int
+	items []T
+
+	// parent[i] is the next item up the tree towards the root of i's component. A root
+	// is its own parent.
+	parent []int
+
+	// size[i] is the number of items in the component, but only for roots
+	size []int
+
+	count int
+}
+
+// NewUnionFind makes a UnionFind with each of `items` in a component of its own
+func NewUnionFind[T comparable](items ...T) *UnionFind[T] {
+	uf := &UnionFind[T]{
+		ids:    make(map[T]int, len(items)),
+		items:  make([]T, 0, len(items)),
+		parent: make([]int, 0, len(items)),
+		size:   make([]int, 0, len(items)),
+	}
+	for _, item := range items {
+		uf.Add(item)
+	}
+	return uf
+}
+
+// Add puts `item` in a component of its own. It returns false if it was already there.
+func (uf *UnionFind[T]) Add(item T) bool {
+	if _, ok := uf.ids[item]; ok {
+		return false
+	}
+	uf.id(item)
+	return true
+}
+
+// id returns the index of `item`, adding it if it is new
+func (uf *UnionFind[T]) id(item T) int {
+	if id, ok := uf.ids[item]; ok {
+		return id
+	}
+	id := len(uf.items)
+	uf.ids[item] = id
+	uf.items = append(uf.items, item)
+	uf.parent = append(uf.parent, id)
+	uf.size = append(uf.size, 1)
+	uf.count++
+	return id
+}
+
+// root finds the root of the component, pointing every other item on the way at its
+// grandparent so the next search is shorter
+func (uf *UnionFind[T]) root(id int) int {
+	for uf.parent[id] != id {
+		uf.parent[id] = uf.parent[uf.parent[id]]
+		id = uf.parent[id]
+	}
+	return id
+}
+
+// Find returns the item that represents the component `item` is in. Two items are in
+// the same component if Find returns the same thing for both. New items are added in a
+// component of their own.
+func (uf *UnionFind[T]) Find(item T) T {
+	return uf.items[uf.root(uf.id(item))]
+}
+
+// Union joins the components of `a` and `b`, adding them if they are new. It returns
+// false if they were already in the same component.
+func (uf *UnionFind[T]) Union(a, b T) bool {
+	ra, rb := uf.root(uf.id(a)), uf.root(uf.id(b))
+	if ra == rb {
+		return false
+	}
+
+	// Hang the smaller tree under the larger one, so trees stay shallow
+	if uf.size[ra] < uf.size[rb] {
+		ra, rb = rb, ra
+	}
+	uf.parent[rb] = ra
+	uf.size[ra] += uf.size[rb]
+	uf.count--
+	return true
+}
+
+// Connected says if `a` and `b` are in the same component. Items that haven't been added
+// are only connected to themselves.
+func (uf *UnionFind[T]) Connected(a, b T) bool {
+	ia, ok_a := uf.ids[a]
+	ib, ok_b := uf.ids[b]
+	if !ok_a || !ok_b {
+		return a == b
+	}
+	return uf.root(ia) == uf.root(ib)
+}
+
+// Size returns how many items are in the same component as `item`, including itself.
+// Items that haven't been added are on their own.
+func (uf *UnionFind[T]) Size(item T) int {
+	id, ok := uf.ids[item]
+	if !ok {
+		return 1
+	}
+	return uf.size[uf.root(id)]
+}
+
+// Len returns the number of items
+func (uf *UnionFind[T]) Len() int {
+	return len(uf.items)
+}
+
+// Count returns the number of components
+func (uf *UnionFind[T]) Count() int {
+	return uf.count
+}
+
+// component_ids numbers each component in the order its first item was added, and
+// returns the number of each item's component
+func (uf *UnionFind[T]) component_ids() []int {
+	// Which component each root is, or -1 if it hasn't been seen yet
+	of_root := make([]int, len(uf.items))
+	for id := range of_root {
+		of_root[id] = -1
+	}
+
+	ids := make([]int, len(uf.items))
+	next := 0
+	for id := range uf.items {
+		r := uf.root(id)
+		if of_root[r] == -1 {
+			of_root[r] = next
+			next++
+		}
+		ids[id] = of_root[r]
+	}
+	return ids
+}
+
+// Components returns the items in each component. Components are in the order their
+// first item was added, and the items in each are in the order they were added.
+func (uf *UnionFind[T]) Components() [][]T {
+	res := make([][]T, uf.count)
+	for id, c := range uf.component_ids() {
+		res[c] = append(res[c], uf.items[id])
+	}
+	return res
+}
+
+// ComponentSizes returns how many items are in each component, in the same order as
+// Components
+func (uf *UnionFind[T]) ComponentSizes() []int {
+	res := make([]int, uf.count)
+	for _, c := range uf.component_ids() {
+		res[c]++
+	}
+	return res
+}
@@ -0,0 +1,136 @@
+package utils
+
+import (
+	"math/rand/v2"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestUnionFind(t *testing.T) {
+	uf := NewUnionFind("a", "b", "c", "d", "e")
+	assert.Equal(t, 5, uf.Len())
+	assert.Equal(t, 5, uf.Count())
+	assert.False(t, uf.Add("a"))
+
+	assert.True(t, uf.Union("a", "b"))
+	assert.True(t, uf.Union("c", "d"))
+	assert.True(t, uf.Union("b", "d"))
+	assert.False(t, uf.Union("a", "c"))
+	assert.Equal(t, 2, uf.Count())
+
+	assert.True(t, uf.Connected("a", "d"))
+	assert.False(t, uf.Connected("a", "e"))
+	assert.Equal(t, uf.Find("a"), uf.Find("c"))
+	assert.NotEqual(t, uf.Find("a"), uf.Find("e"))
+	assert.Equal(t, 4, uf.Size("b"))
+	assert.Equal(t, 1, uf.Size("e"))
+
+	// New items turn up in a component of their own
+	assert.False(t, uf.Connected("x", "a"))
+	assert.True(t, uf.Connected("x", "x"))
+	assert.Equal(t, 1, uf.Size("x"))
+	assert.Equal(t, 5, uf.Len())
+	assert.True(t, uf.Union("e", "f"))
+	assert.Equal(t, 6, uf.Len())
+	assert.Equal(t, 2, uf.Count())
+
+	assert.Equal(t, [][]string{{"a", "b", "c", "d"}, {"e", "f"}}, uf.Components())
+	assert.Equal(t, []int{4, 2}, uf.ComponentSizes())
+}
+
+func TestUnionFindRandom(t *testing.T) {
+	// Check against a slow version that relabels everything on each union
+	r := rand.New(rand.NewPCG(7, 8))
+	n := 200
+	uf := NewUnionFind[int]()
+	label := make([]int, n)
+	for i := range n {
+		uf.Add(i)
+		label[i] = i
+	}
+
+	for range 300 {
+		a, b := r.IntN(n), r.IntN(n)
+		merged := label[a] != label[b]
+		assert.Equal(t, merged, uf.Union(a, b))
+		if merged {
+			old := label[b]
+			for i := range label {
+				if label[i] == old {
+					label[i] = label[a]
+				}
+			}
+		}
+
+		x, y := r.IntN(n), r.IntN(n)
+		assert.Equal(t, label[x] == label[y], uf.Connected(x, y))
+	}
+
+	sizes := make(map[int]int)
+	for _, l := range label {
+		sizes[l]++
+	}
+	assert.Equal(t, len(sizes), uf.Count())
+	for i := range n {
+		assert.Equal(t, sizes[label[i]], uf.Size(i))
+	}
+
+	total := 0
+	for _, s := range uf.ComponentSizes() {
+		total += s
+	}
+	assert.Equal(t, n, total)
+}
+
+const uf_bench_n = 1_000_000
+
+func BenchmarkUnionFindUnion(b *testing.B) {
+	r := rand.New(rand.NewPCG(1, 1))
+	pairs := make([][2]int, uf_bench_n)
+	for i := range pairs {
+		pairs[i] = [2]int{r.IntN(uf_bench_n), r.IntN(uf_bench_n)}
+	}
+
+	for b.Loop() {
+		uf := NewUnionFind[int]()
+		for i := range uf_bench_n {
+			uf.Add(i)
+		}
+		for _, p := range pairs {
+			uf.Union(p[0], p[1])
+		}
+	}
+}
+
+func BenchmarkUnionFindFind(b *testing.B) {
+	r := rand.New(rand.NewPCG(1, 1))
+	uf := NewUnionFind[int]()
+	for i := range uf_bench_n {
+		uf.Add(i)
+	}
+	for range uf_bench_n {
+		uf.Union(r.IntN(uf_bench_n), r.IntN(uf_bench_n))
+	}
+
+	for b.Loop() {
+		for i := range uf_bench_n {
+			uf.Find(i)
+		}
+	}
+}
+
+func BenchmarkUnionFindComponents(b *testing.B) {
+	r := rand.New(rand.NewPCG(1, 1))
+	uf := NewUnionFind[int]()
+	for i := range uf_bench_n {
+		uf.Add(i)
+	}
+	for range uf_bench_n {
+		uf.Union(r.IntN(uf_bench_n), r.IntN(uf_bench_n))
+	}
+
+	for b.Loop() {
+		uf.Components()
+	}
+}
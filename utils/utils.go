@@ -2,8 +2,8 @@ package utils
 
 import (
 	"fmt"
+	"iter"
 	"os"
-	"regexp"
 	"slices"
 	"strconv"
 	"strings"
@@ -48,16 +48,6 @@ func ParseBool(s string) bool {
 	panic("invalid boolean string")
 }
 
-// GetGroups is essentially the same as FindAllStringSubmatch, but it returns
-// just the groups and not the full match
-func GetGroups(re *regexp.Regexp, s string) [][]string {
-	matches := re.FindAllStringSubmatch(s, -1)
-	for _, match := range matches {
-		match = slices.Delete(match, 0, 1)
-	}
-	return matches
-}
-
 // DiGraph is a directed graph that stores the edges in both directions. This is
 // necessary to be able to traverse the graph in both directions. The graph is
 // represented as a map of edges.
@@ -91,6 +81,16 @@ func (g *DiGraph[T]) AddEdge(from, to T) {
 	}
 }
 
+// Successors yields every node that `n` has an edge to
+func (g *DiGraph[T]) Successors(n T) iter.Seq[T] {
+	return slices.Values(g.from_to[n])
+}
+
+// Predecessors yields every node that has an edge to `n`
+func (g *DiGraph[T]) Predecessors(n T) iter.Seq[T] {
+	return slices.Values(g.to_from[n])
+}
+
 // RemoveEdge removes an edge from the graph. It removes the edge in both directions.
 func (g *DiGraph[T]) RemoveEdge(from, to T) {
 	g.from_to[from] = slices.DeleteFunc(g.from_to[from], func(w T) bool { return w == to })
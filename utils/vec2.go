@@ -0,0 +1,106 @@
+package utils
+
+import "fmt"
+
+// Vec2 is a 2D point or vector with integer coordinates. It follows the convention of
+// most puzzle boards: X increases going right, and Y increases going *down*.
+type Vec2 struct {
+	X, Y int
+}
+
+// The four cardinal directions, as unit vectors. Up has a negative Y because Y increases
+// going down.
+var (
+	Up    = Vec2{0, -1}
+	Right = Vec2{1, 0}
+	Down  = Vec2{0, 1}
+	Left  = Vec2{-1, 0}
+)
+
+// Dirs4 are the four cardinal directions, going clockwise from Up
+var Dirs4 = [4]Vec2{Up, Right, Down, Left}
+
+// Dirs8 are the four cardinal directions and the four diagonals, going clockwise from Up
+var Dirs8 = [8]Vec2{Up, {1, -1}, Right, {1, 1}, Down, {-1, 1}, Left, {-1, -1}}
+
+func (v Vec2) String() string {
+	return fmt.Sprintf("(%d, %d)", v.X, v.Y)
+}
+
+// Add returns v + o
+func (v Vec2) Add(o Vec2) Vec2 {
+	return Vec2{v.X + o.X, v.Y + o.Y}
+}
+
+// Sub returns v - o
+func (v Vec2) Sub(o Vec2) Vec2 {
+	return Vec2{v.X - o.X, v.Y - o.Y}
+}
+
+// Scale returns v multiplied by k
+func (v Vec2) Scale(k int) Vec2 {
+	return Vec2{v.X * k, v.Y * k}
+}
+
+// Neg returns -v
+func (v Vec2) Neg() Vec2 {
+	return Vec2{-v.X, -v.Y}
+}
+
+// Cross returns the z component of the cross product v × o, which is also the
+// determinant of the 2x2 matrix with v and o as its columns. It is 0 when v and o are
+// parallel.
+func (v Vec2) Cross(o Vec2) int {
+	return v.X*o.Y - v.Y*o.X
+}
+
+// Dot returns the dot product of v and o
+func (v Vec2) Dot(o Vec2) int {
+	return v.X*o.X + v.Y*o.Y
+}
+
+// Manhattan returns the taxicab distance between v and o, i.e. the number of steps in
+// the four cardinal directions to get from one to the other.
+func (v Vec2) Manhattan(o Vec2) int {
+	return abs(v.X-o.X) + abs(v.Y-o.Y)
+}
+
+// Chebyshev returns the chessboard distance between v and o, i.e. the number of king
+// moves (any of the 8 directions) to get from one to the other.
+func (v Vec2) Chebyshev(o Vec2) int {
+	return max(abs(v.X-o.X), abs(v.Y-o.Y))
+}
+
+// RotateCW turns v 90 degrees clockwise as seen on the board, so Up becomes Right.
+func (v Vec2) RotateCW() Vec2 {
+	return Vec2{-v.Y, v.X}
+}
+
+// RotateCCW turns v 90 degrees counter-clockwise as seen on the board, so Up becomes
+// Left.
+func (v Vec2) RotateCCW() Vec2 {
+	return Vec2{v.Y, -v.X}
+}
+
+// WrapMod wraps v onto a width x height torus, so that both coordinates end up in
+// [0, width) and [0, height), even when they start out negative.
+func (v Vec2) WrapMod(width, height int) Vec2 {
+	return Vec2{Mod(v.X, width), Mod(v.Y, height)}
+}
+
+// Mod returns a modulo m in the range [0, m), unlike `%` which keeps the sign of a. E.g.
+// Mod(-1, 5) is 4, where -1 % 5 is -1.
+func Mod(a, m int) int {
+	r := a % m
+	if r < 0 {
+		r += m
+	}
+	return r
+}
+
+func abs(x int) int {
+	if x < 0 {
+		return -x
+	}
+	return x
+}
@@ -0,0 +1,87 @@
+package utils
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestVec2Arithmetic(t *testing.T) {
+	a := Vec2{3, -4}
+	b := Vec2{-1, 2}
+	assert.Equal(t, Vec2{2, -2}, a.Add(b))
+	assert.Equal(t, Vec2{4, -6}, a.Sub(b))
+	assert.Equal(t, Vec2{-9, 12}, a.Scale(-3))
+	assert.Equal(t, Vec2{-3, 4}, a.Neg())
+	assert.Equal(t, 2, a.Cross(b))
+	assert.Equal(t, -11, a.Dot(b))
+	assert.Equal(t, 0, a.Cross(a.Scale(2)))
+	assert.Equal(t, "(3, -4)", a.String())
+}
+
+func TestVec2Distances(t *testing.T) {
+	tests := []struct {
+		name      string
+		a, b      Vec2
+		manhattan int
+		chebyshev int
+	}{
+		{"same", Vec2{1, 1}, Vec2{1, 1}, 0, 0},
+		{"line", Vec2{0, 0}, Vec2{5, 0}, 5, 5},
+		{"diagonal", Vec2{0, 0}, Vec2{-3, 3}, 6, 3},
+		{"mixed", Vec2{2, -1}, Vec2{-2, 6}, 11, 7},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.manhattan, tc.a.Manhattan(tc.b))
+			assert.Equal(t, tc.manhattan, tc.b.Manhattan(tc.a))
+			assert.Equal(t, tc.chebyshev, tc.a.Chebyshev(tc.b))
+		})
+	}
+}
+
+func TestVec2Rotate(t *testing.T) {
+	for idx, d := range Dirs4 {
+		assert.Equal(t, Dirs4[(idx+1)%4], d.RotateCW())
+		assert.Equal(t, Dirs4[(idx+3)%4], d.RotateCCW())
+	}
+	v := Vec2{2, 1}
+	assert.Equal(t, v, v.RotateCW().RotateCW().RotateCW().RotateCW())
+	assert.Equal(t, v.Neg(), v.RotateCCW().RotateCCW())
+
+	// The diagonals in Dirs8 sit between the cardinal directions
+	for idx := range Dirs4 {
+		assert.Equal(t, Dirs8[2*idx], Dirs4[idx])
+		assert.Equal(t, Dirs8[2*idx+1], Dirs4[idx].Add(Dirs4[(idx+1)%4]))
+	}
+}
+
+func TestMod(t *testing.T) {
+	tests := []struct {
+		a, m, want int
+	}{
+		{7, 5, 2}, {-1, 5, 4}, {-5, 5, 0}, {-6, 5, 4}, {0, 3, 0}, {-301, 101, 2},
+	}
+	for _, tc := range tests {
+		assert.Equal(t, tc.want, Mod(tc.a, tc.m))
+	}
+}
+
+func TestWrapMod(t *testing.T) {
+	tests := []struct {
+		name string
+		v    Vec2
+		want Vec2
+	}{
+		{"inside", Vec2{3, 4}, Vec2{3, 4}},
+		{"past edge", Vec2{11, 7}, Vec2{0, 0}},
+		{"negative", Vec2{-1, -1}, Vec2{10, 6}},
+		{"far negative", Vec2{-23, -15}, Vec2{10, 6}},
+		{"far positive", Vec2{100, 100}, Vec2{1, 2}},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.want, tc.v.WrapMod(11, 7))
+		})
+	}
+}